@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -10,6 +11,7 @@ import (
 
 type Client struct {
 	bot            *tgbotapi.BotAPI
+	limiter        *rateLimiter
 	UpdatesChannel tgbotapi.UpdatesChannel
 }
 
@@ -31,6 +33,7 @@ func NewClient(envCfg config.EnvConfig) (*Client, error) {
 
 	return &Client{
 		bot:            bot,
+		limiter:        newRateLimiter(),
 		UpdatesChannel: updates,
 	}, nil
 }
@@ -42,14 +45,39 @@ func NewClient(envCfg config.EnvConfig) (*Client, error) {
 //	return c.Bot.GetUpdatesChan(cfg)
 //}
 
-func (c *Client) SendPlainMessage(chatID int64, message string) error {
+func (c *Client) SendPlainMessage(ctx context.Context, chatID int64, message string) error {
 	msg := tgbotapi.NewMessage(chatID, message)
-	if _, err := c.bot.Send(msg); err != nil {
+	if _, err := c.send(ctx, chatID, msg); err != nil {
 		return fmt.Errorf("bot failed to send plain message [messageConfig: %+v]: %w", msg, err)
 	}
 	return nil
 }
 
+func (c *Client) SendMarkupMessage(ctx context.Context, chatID int64, text string, markup interface{}) (int,
+	error) {
+	return c.sendMarkupMessage(ctx, chatID, text, markup, false)
+}
+
+// SendSilentMarkupMessage sends a markup message that arrives without a notification sound.
+func (c *Client) SendSilentMarkupMessage(ctx context.Context, chatID int64, text string, markup interface{}) (int,
+	error) {
+	return c.sendMarkupMessage(ctx, chatID, text, markup, true)
+}
+
+func (c *Client) sendMarkupMessage(ctx context.Context, chatID int64, text string, markup interface{},
+	isSilent bool) (int, error) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = markup
+	msg.DisableNotification = isSilent
+
+	sent, err := c.send(ctx, chatID, msg)
+	if err != nil {
+		return 0, fmt.Errorf("bot failed to send markup message [messageConfig: %+v]: %w", msg, err)
+	}
+	return sent.MessageID, nil
+}
+
+// SendCallbackConfig answers a callback query. Answers are not messages, so they skip the rate limiter.
 func (c *Client) SendCallbackConfig(queryID, text string) error {
 	callbackCfg := tgbotapi.NewCallback(queryID, text)
 	if _, err := c.bot.Send(callbackCfg); err != nil {
@@ -58,22 +86,49 @@ func (c *Client) SendCallbackConfig(queryID, text string) error {
 	return nil
 }
 
-func (c *Client) SendHtmlMessage(chatID int64, text string, markup interface{}) error {
+func (c *Client) SendHtmlMessage(ctx context.Context, chatID int64, text string, markup interface{}) error {
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = tgbotapi.ModeHTML
 	msg.ReplyMarkup = markup
 
-	if _, err := c.bot.Send(msg); err != nil {
+	if _, err := c.send(ctx, chatID, msg); err != nil {
 		return fmt.Errorf("bot failed to send html message [messageConfig: %+v]: %w", msg, err)
 	}
 	return nil
 }
 
-func (c *Client) SendEditMessage(chatID int64, messageID int, text string) error {
+func (c *Client) SendEditMessage(ctx context.Context, chatID int64, messageID int, text string) error {
 	msg := tgbotapi.NewEditMessageText(chatID, messageID, text)
 
-	if _, err := c.bot.Send(msg); err != nil {
+	if _, err := c.send(ctx, chatID, msg); err != nil {
 		return fmt.Errorf("bot failed to send edit message [messageConfig: %+v]: %w", msg, err)
 	}
 	return nil
 }
+
+func (c *Client) SendEditMarkupMessage(ctx context.Context, chatID int64, messageID int, text string,
+	markup *tgbotapi.InlineKeyboardMarkup) error {
+	msg := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	msg.ReplyMarkup = markup
+
+	if _, err := c.send(ctx, chatID, msg); err != nil {
+		return fmt.Errorf("bot failed to send edit markup message [messageConfig: %+v]: %w", msg, err)
+	}
+	return nil
+}
+
+// send waits for the rate limiter before sending to the chat, and holds back the chat's later messages for as long as
+// Telegram asks when it still rate limits the bot.
+func (c *Client) send(ctx context.Context, chatID int64, chattable tgbotapi.Chattable) (tgbotapi.Message,
+	error) {
+	if err := c.limiter.wait(ctx, chatID); err != nil {
+		return tgbotapi.Message{}, fmt.Errorf("bot gave up waiting for the rate limiter [telegramChatID: %v]: %w",
+			chatID, err)
+	}
+
+	sent, err := c.bot.Send(chattable)
+	if retryAfter, ok := RetryAfter(err); ok {
+		c.limiter.block(chatID, retryAfter)
+	}
+	return sent, err
+}
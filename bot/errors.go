@@ -0,0 +1,38 @@
+package bot
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// RetryAfter returns how long Telegram asked us to wait before retrying a request that hit its flood control.
+func RetryAfter(err error) (time.Duration, bool) {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter <= 0 {
+		return 0, false
+	}
+	return time.Duration(apiErr.RetryAfter) * time.Second, true
+}
+
+// IsPermanentError reports whether Telegram rejected a request in a way that retrying cannot fix, e.g. a bad
+// request or a chat that blocked the bot. Network errors and server errors are not permanent.
+func IsPermanentError(err error) bool {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter > 0 {
+		return false
+	}
+	switch apiErr.Code {
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return true
+	}
+	return false
+}
+
+// IsForbiddenError reports whether the bot is no longer allowed to message the chat, e.g. it was blocked or kicked.
+func IsForbiddenError(err error) bool {
+	var apiErr *tgbotapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden
+}
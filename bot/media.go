@@ -0,0 +1,152 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	MediaTypePhoto    = "photo"
+	MediaTypeDocument = "document"
+	MediaTypeVoice    = "voice"
+	MediaTypeSticker  = "sticker"
+)
+
+// Media is a file that was sent to the bot, which it can send again by its Telegram file ID.
+type Media struct {
+	Type   string `json:"type"`
+	FileID string `json:"file_id"`
+}
+
+func (m Media) IsZero() bool {
+	return m.FileID == ""
+}
+
+// MediaFromMessage returns the photo, document, voice note or sticker of a message.
+func MediaFromMessage(message *tgbotapi.Message) (Media, bool) {
+	switch {
+	case len(message.Photo) > 0:
+		// a photo comes in several sizes, the largest last
+		return Media{Type: MediaTypePhoto, FileID: message.Photo[len(message.Photo)-1].FileID}, true
+	case message.Document != nil:
+		return Media{Type: MediaTypeDocument, FileID: message.Document.FileID}, true
+	case message.Voice != nil:
+		return Media{Type: MediaTypeVoice, FileID: message.Voice.FileID}, true
+	case message.Sticker != nil:
+		return Media{Type: MediaTypeSticker, FileID: message.Sticker.FileID}, true
+	}
+	return Media{}, false
+}
+
+// SendMediaMarkupMessage sends media with the text as its caption, or just the text if there is no media.
+func (c *Client) SendMediaMarkupMessage(ctx context.Context, chatID int64, media Media, text string,
+	markup interface{}) (int, error) {
+	return c.sendMediaMarkupMessage(ctx, chatID, media, text, markup, false)
+}
+
+// SendSilentMediaMarkupMessage sends a media markup message that arrives without a notification sound.
+func (c *Client) SendSilentMediaMarkupMessage(ctx context.Context, chatID int64, media Media, text string,
+	markup interface{}) (int, error) {
+	return c.sendMediaMarkupMessage(ctx, chatID, media, text, markup, true)
+}
+
+func (c *Client) sendMediaMarkupMessage(ctx context.Context, chatID int64, media Media, text string,
+	markup interface{}, isSilent bool) (int, error) {
+	if media.IsZero() {
+		return c.sendMarkupMessage(ctx, chatID, text, markup, isSilent)
+	}
+
+	file := tgbotapi.FileID(media.FileID)
+	var chattable tgbotapi.Chattable
+	switch media.Type {
+	case MediaTypePhoto:
+		photo := tgbotapi.NewPhoto(chatID, file)
+		photo.Caption = text
+		photo.ReplyMarkup = markup
+		photo.DisableNotification = isSilent
+		chattable = photo
+	case MediaTypeDocument:
+		document := tgbotapi.NewDocument(chatID, file)
+		document.Caption = text
+		document.ReplyMarkup = markup
+		document.DisableNotification = isSilent
+		chattable = document
+	case MediaTypeVoice:
+		voice := tgbotapi.NewVoice(chatID, file)
+		voice.Caption = text
+		voice.ReplyMarkup = markup
+		voice.DisableNotification = isSilent
+		chattable = voice
+	case MediaTypeSticker:
+		if text != "" {
+			return c.sendStickerMarkupMessage(ctx, chatID, file, text, markup, isSilent)
+		}
+		sticker := tgbotapi.NewSticker(chatID, file)
+		sticker.ReplyMarkup = markup
+		sticker.DisableNotification = isSilent
+		chattable = sticker
+	default:
+		return 0, fmt.Errorf("bot cannot send media of unknown type [media: %+v]", media)
+	}
+
+	sent, err := c.send(ctx, chatID, chattable)
+	if err != nil {
+		return 0, fmt.Errorf("bot failed to send media markup message [media: %+v][text: %s]: %w", media, text, err)
+	}
+	return sent.MessageID, nil
+}
+
+// sendStickerMarkupMessage sends the text and its buttons as one message, with the sticker just after it, since
+// stickers cannot have a caption. The reminder has been sent once its text has, so a sticker that fails to follow it
+// is only logged, and the text is never sent twice.
+func (c *Client) sendStickerMarkupMessage(ctx context.Context, chatID int64, file tgbotapi.FileID, text string,
+	markup interface{}, isSilent bool) (int, error) {
+	messageID, err := c.sendMarkupMessage(ctx, chatID, text, markup, isSilent)
+	if err != nil {
+		return 0, err
+	}
+
+	sticker := tgbotapi.NewSticker(chatID, file)
+	sticker.DisableNotification = isSilent
+	if _, err := c.send(ctx, chatID, sticker); err != nil {
+		log.Warn().Err(err).Msgf("Unable to send sticker after its text [telegramChatID: %v][messageID: %v].", chatID,
+			messageID)
+	}
+	return messageID, nil
+}
+
+// SendEditMediaMessage replaces the text of a message sent with SendMediaMarkupMessage and removes its buttons. The
+// caption is edited for media, while a sticker sent without text, which has neither, only loses its buttons.
+func (c *Client) SendEditMediaMessage(ctx context.Context, chatID int64, messageID int, media Media,
+	text string) error {
+	var chattable tgbotapi.Chattable
+	switch {
+	case media.IsZero():
+		return c.SendEditMessage(ctx, chatID, messageID, text)
+	case media.Type == MediaTypeSticker:
+		chattable = tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, tgbotapi.InlineKeyboardMarkup{
+			InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
+		})
+	default:
+		chattable = tgbotapi.NewEditMessageCaption(chatID, messageID, text)
+	}
+
+	if _, err := c.send(ctx, chatID, chattable); err != nil {
+		return fmt.Errorf("bot failed to send edit media message [media: %+v][text: %s]: %w", media, text, err)
+	}
+	return nil
+}
+
+// DescribeMediaMessage describes a message that may have media for job listings, e.g. "📎 photo: Parking spot".
+func DescribeMediaMessage(mediaType, text string) string {
+	switch {
+	case mediaType == "":
+		return text
+	case text == "":
+		return "📎 " + mediaType
+	}
+	return fmt.Sprintf("📎 %s: %s", mediaType, text)
+}
@@ -0,0 +1,174 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Telegram's flood limits: about 30 messages per second overall, 1 message per second to a chat and 20 messages per
+// minute to a group.
+const (
+	globalMessagesPerSecond = 30
+	chatMessagesPerSecond   = 1
+	groupMessagesPerMinute  = 20
+	// a bucket lets through its burst on top of what it refills, so the group bucket refills a little slower than the
+	// limit to keep any minute within it.
+	groupBurst = 3
+	// pruneBucketsAfter is how many chat buckets are kept before the idle ones are dropped.
+	pruneBucketsAfter = 1024
+)
+
+// tokenBucket holds up to burst tokens and refills at rate tokens per second. Its tokens go negative when sends are
+// reserved ahead of time, so that each waiting send gets its own slot.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate, burst float64, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if now.After(b.last) {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// availableAt returns when the bucket next has a token to take.
+func (b *tokenBucket) availableAt(now time.Time) time.Time {
+	if b.tokens >= 1 {
+		return now
+	}
+	return now.Add(time.Duration((1 - b.tokens) / b.rate * float64(time.Second)))
+}
+
+func (b *tokenBucket) isIdle(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.burst
+}
+
+// rateLimiter queues outbound messages so that they stay within Telegram's flood limits: a send first waits for its
+// chat's buckets, then for the global bucket, so that a busy chat does not hold up everyone else. The buckets are kept
+// in memory, so the limits apply to each running instance of the bot on its own: instances sharing a bot token share
+// Telegram's limits too, and may still be rate limited, which Telegram's retry after then holds back.
+type rateLimiter struct {
+	mu     sync.Mutex
+	global *tokenBucket
+	chats  map[int64][]*tokenBucket
+	// blockedUntil is when each chat that Telegram rate limited may be messaged again.
+	blockedUntil map[int64]time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		global:       newTokenBucket(globalMessagesPerSecond, globalMessagesPerSecond, time.Now()),
+		chats:        make(map[int64][]*tokenBucket),
+		blockedUntil: make(map[int64]time.Time),
+	}
+}
+
+// wait blocks until a message may be sent to the chat, or until ctx is done. A send given up on keeps its reserved
+// slot, which only delays later sends to the chat.
+func (l *rateLimiter) wait(ctx context.Context, chatID int64) error {
+	if err := sleepContext(ctx, l.reserveChat(chatID, time.Now())); err != nil {
+		return err
+	}
+	return sleepContext(ctx, l.reserve(l.global, time.Now()))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserveChat takes a token from each of the chat's buckets and returns how long the send must wait for them.
+func (l *rateLimiter) reserveChat(chatID int64, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	buckets, ok := l.chats[chatID]
+	if !ok {
+		if len(l.chats) >= pruneBucketsAfter {
+			l.prune(now)
+		}
+		buckets = []*tokenBucket{newTokenBucket(chatMessagesPerSecond, chatMessagesPerSecond, now)}
+		// groups, supergroups and channels have negative chat IDs
+		if chatID < 0 {
+			buckets = append(buckets, newTokenBucket((groupMessagesPerMinute-groupBurst)/60.0, groupBurst, now))
+		}
+		l.chats[chatID] = buckets
+	}
+
+	at := now
+	if blockedUntil, ok := l.blockedUntil[chatID]; ok {
+		if blockedUntil.After(now) {
+			at = blockedUntil
+		} else {
+			delete(l.blockedUntil, chatID)
+		}
+	}
+	for _, bucket := range buckets {
+		bucket.refill(now)
+		if availableAt := bucket.availableAt(now); availableAt.After(at) {
+			at = availableAt
+		}
+	}
+	for _, bucket := range buckets {
+		bucket.tokens--
+	}
+	return at.Sub(now)
+}
+
+func (l *rateLimiter) reserve(bucket *tokenBucket, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket.refill(now)
+	at := bucket.availableAt(now)
+	bucket.tokens--
+	return at.Sub(now)
+}
+
+// block holds back messages to the chat after Telegram asked us to wait before retrying.
+func (l *rateLimiter) block(chatID int64, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	blockedUntil := time.Now().Add(retryAfter)
+	if blockedUntil.After(l.blockedUntil[chatID]) {
+		l.blockedUntil[chatID] = blockedUntil
+	}
+}
+
+// prune drops the buckets of chats that have not been messaged for long enough that their buckets are full again.
+func (l *rateLimiter) prune(now time.Time) {
+	for chatID, buckets := range l.chats {
+		isIdle := true
+		for _, bucket := range buckets {
+			isIdle = isIdle && bucket.isIdle(now)
+		}
+		if isIdle {
+			delete(l.chats, chatID)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+	bucket := newTokenBucket(2, 4, now)
+
+	if got := bucket.availableAt(now); !got.Equal(now) {
+		t.Errorf("availableAt of a full bucket = %v, want %v", got, now)
+	}
+
+	bucket.tokens = -1
+	if got, want := bucket.availableAt(now), now.Add(time.Second); !got.Equal(want) {
+		t.Errorf("availableAt of an overdrawn bucket = %v, want %v", got, want)
+	}
+
+	bucket.refill(now.Add(500 * time.Millisecond))
+	if bucket.tokens != 0 {
+		t.Errorf("tokens after refilling for 0.5s = %v, want 0", bucket.tokens)
+	}
+
+	// a bucket never holds more than its burst
+	bucket.refill(now.Add(time.Hour))
+	if bucket.tokens != 4 {
+		t.Errorf("tokens after refilling for an hour = %v, want 4", bucket.tokens)
+	}
+	if !bucket.isIdle(now.Add(time.Hour)) {
+		t.Error("full bucket is not idle")
+	}
+
+	// time going backwards does not refill
+	bucket.tokens = 0
+	bucket.refill(now)
+	if bucket.tokens != 0 {
+		t.Errorf("tokens after refilling in the past = %v, want 0", bucket.tokens)
+	}
+}
+
+func TestRateLimiterReserveChat(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		chatID int64
+		want   []time.Duration
+	}{
+		{
+			name:   "private chat",
+			chatID: 123,
+			want:   []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second, 4 * time.Second},
+		},
+		{
+			// after its burst of 3, a group gets a message every 60/17 seconds
+			name:   "group",
+			chatID: -123,
+			want:   []time.Duration{0, time.Second, 2 * time.Second, time.Minute / 17, 2 * time.Minute / 17},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := newRateLimiter()
+			for i, want := range tt.want {
+				if got := limiter.reserveChat(tt.chatID, now); !durationsEqual(got, want) {
+					t.Errorf("reserveChat #%d = %v, want %v", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterGroupLimit(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter()
+
+	var sentWithinMinute int
+	for range 2 * groupMessagesPerMinute {
+		if limiter.reserveChat(-123, now) < time.Minute {
+			sentWithinMinute++
+		}
+	}
+	if sentWithinMinute > groupMessagesPerMinute {
+		t.Errorf("%d messages sent to a group within a minute, want at most %d", sentWithinMinute,
+			groupMessagesPerMinute)
+	}
+}
+
+func TestRateLimiterReserveGlobal(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter()
+	limiter.global = newTokenBucket(globalMessagesPerSecond, globalMessagesPerSecond, now)
+
+	for i := range globalMessagesPerSecond {
+		if got := limiter.reserve(limiter.global, now); got != 0 {
+			t.Fatalf("reserve #%d = %v, want 0", i+1, got)
+		}
+	}
+	want := time.Second / globalMessagesPerSecond
+	if got := limiter.reserve(limiter.global, now); !durationsEqual(got, want) {
+		t.Errorf("reserve after the burst = %v, want %v", got, want)
+	}
+}
+
+func TestRateLimiterBlock(t *testing.T) {
+	limiter := newRateLimiter()
+	limiter.block(123, 5*time.Second)
+	// a shorter retry after does not shorten the block
+	limiter.block(123, time.Second)
+
+	if got := limiter.reserveChat(123, time.Now()); got < 4*time.Second || got > 5*time.Second {
+		t.Errorf("reserveChat of a blocked chat = %v, want about 5s", got)
+	}
+	if got := limiter.reserveChat(456, time.Now()); got != 0 {
+		t.Errorf("reserveChat of another chat = %v, want 0", got)
+	}
+
+	// the block is lifted once it has passed
+	if got := limiter.reserveChat(123, time.Now().Add(time.Minute)); got != 0 {
+		t.Errorf("reserveChat after the block = %v, want 0", got)
+	}
+	if _, ok := limiter.blockedUntil[123]; ok {
+		t.Error("block was not removed after it passed")
+	}
+}
+
+func TestRateLimiterWaitContext(t *testing.T) {
+	limiter := newRateLimiter()
+	if err := limiter.wait(context.Background(), 123); err != nil {
+		t.Fatalf("wait returned error: %v", err)
+	}
+
+	limiter.block(123, time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := limiter.wait(ctx, 123); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wait error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("wait took %v after its context was done", elapsed)
+	}
+}
+
+func TestRateLimiterPrune(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter()
+	limiter.reserveChat(123, now)
+	limiter.reserveChat(-123, now)
+	limiter.reserveChat(456, now.Add(time.Minute))
+
+	limiter.prune(now.Add(time.Minute))
+	if _, ok := limiter.chats[123]; ok {
+		t.Error("idle private chat was not pruned")
+	}
+	if _, ok := limiter.chats[-123]; ok {
+		t.Error("idle group was not pruned")
+	}
+	if _, ok := limiter.chats[456]; !ok {
+		t.Error("busy chat was pruned")
+	}
+}
+
+func durationsEqual(got, want time.Duration) bool {
+	diff := got - want
+	return diff > -time.Millisecond && diff < time.Millisecond
+}
@@ -0,0 +1,97 @@
+package calendars
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+//go:embed data/*.csv
+var dataFS embed.FS
+
+type Holiday struct {
+	Date time.Time
+	Name string
+}
+
+var loadBundled = sync.OnceValue(func() map[string][]Holiday {
+	bundled := make(map[string][]Holiday)
+	entries, err := dataFS.ReadDir("data")
+	if err != nil {
+		log.Err(err).Msg("Unable to read bundled calendars.")
+		return bundled
+	}
+
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+		holidays, err := parseHolidays(path.Join("data", entry.Name()))
+		if err != nil {
+			log.Err(err).Msgf("Unable to parse bundled calendar [name: %s].", name)
+			continue
+		}
+		bundled[name] = holidays
+	}
+	return bundled
+})
+
+// parseHolidays reads a data file of "YYYY-MM-DD,Name" lines, ignoring blank lines and # comments.
+func parseHolidays(file string) ([]Holiday, error) {
+	f, err := dataFS.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open calendar [file: %s]: %w", file, err)
+	}
+	defer f.Close()
+
+	var holidays []Holiday
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		dateText, name, _ := strings.Cut(line, ",")
+		date, err := time.Parse(time.DateOnly, dateText)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse calendar date [file: %s][line: %s]: %w", file, line, err)
+		}
+		holidays = append(holidays, Holiday{Date: date, Name: strings.TrimSpace(name)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar [file: %s]: %w", file, err)
+	}
+	return holidays, nil
+}
+
+// IsBundled reports whether name is one of the public holiday calendars shipped with the bot, e.g. holidays-sg.
+func IsBundled(name string) bool {
+	_, exists := loadBundled()[name]
+	return exists
+}
+
+func BundledNames() []string {
+	bundled := loadBundled()
+	names := make([]string, 0, len(bundled))
+	for name := range bundled {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// BundledHoliday returns the holiday on the given date in a bundled calendar, if there is one.
+func BundledHoliday(name string, year int, month time.Month, day int) (Holiday, bool) {
+	for _, holiday := range loadBundled()[name] {
+		if holiday.Date.Year() == year && holiday.Date.Month() == month && holiday.Date.Day() == day {
+			return holiday, true
+		}
+	}
+	return Holiday{}, false
+}
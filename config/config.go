@@ -1,6 +1,9 @@
 package config
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 type EnvConfig struct {
 	Env              string `env:"ENV" envDefault:"dev"`
@@ -8,6 +11,18 @@ type EnvConfig struct {
 	TelegramBotToken string `env:"TELEGRAM_BOT_TOKEN"`
 	BaseURL          string `env:"BASE_URL"`
 	DeepSeekAPIKey   string `env:"DEEP_SEEK_API_KEY"`
+
+	// each job kind is worked from its own River queue, e.g. RIVER_NAG_QUEUE_MAX_WORKERS=5
+	ScheduledQueue QueueConfig `envPrefix:"RIVER_SCHEDULED_QUEUE_"`
+	PeriodicQueue  QueueConfig `envPrefix:"RIVER_PERIODIC_QUEUE_"`
+	SnoozeQueue    QueueConfig `envPrefix:"RIVER_SNOOZE_QUEUE_"`
+	NagQueue       QueueConfig `envPrefix:"RIVER_NAG_QUEUE_"`
+}
+
+// QueueConfig configures the River queue of a job kind. Zero values fall back to the kind's defaults.
+type QueueConfig struct {
+	MaxWorkers int           `env:"MAX_WORKERS"`
+	Timeout    time.Duration `env:"TIMEOUT"`
 }
 
 func (c EnvConfig) IsDev() bool {
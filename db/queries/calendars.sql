@@ -0,0 +1,58 @@
+-- name: CreateCalendar :one
+INSERT INTO calendars (telegram_chat_id, name)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetCalendarByName :one
+SELECT id, telegram_chat_id, name
+FROM calendars
+WHERE telegram_chat_id = $1
+AND name = $2
+AND deleted_at IS NULL;
+
+-- name: GetCalendarsByTelegramChatID :many
+SELECT calendars.id, calendars.name, COUNT(calendar_dates.id) AS date_count
+FROM calendars
+LEFT JOIN calendar_dates ON calendar_dates.calendar_id = calendars.id AND calendar_dates.deleted_at IS NULL
+WHERE calendars.telegram_chat_id = $1
+AND calendars.deleted_at IS NULL
+GROUP BY calendars.id, calendars.name
+ORDER BY calendars.name;
+
+-- name: DeleteCalendar :one
+UPDATE calendars
+SET deleted_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: AddCalendarDate :one
+INSERT INTO calendar_dates (calendar_id, date)
+VALUES ($1, $2)
+ON CONFLICT (calendar_id, date) DO UPDATE
+SET deleted_at = NULL
+RETURNING *;
+
+-- name: RemoveCalendarDate :execrows
+UPDATE calendar_dates
+SET deleted_at = NOW()
+WHERE calendar_id = $1
+AND date = $2
+AND deleted_at IS NULL;
+
+-- name: GetCalendarDates :many
+SELECT date
+FROM calendar_dates
+WHERE calendar_id = $1
+AND deleted_at IS NULL
+ORDER BY date;
+
+-- name: GetExcludingCalendarName :one
+SELECT calendars.name
+FROM calendars
+JOIN calendar_dates ON calendar_dates.calendar_id = calendars.id AND calendar_dates.deleted_at IS NULL
+WHERE calendars.telegram_chat_id = $1
+AND calendars.name = ANY($2::TEXT[])
+AND calendar_dates.date = $3
+AND calendars.deleted_at IS NULL
+ORDER BY calendars.name
+LIMIT 1;
@@ -1,5 +1,5 @@
 -- name: GetChat :one
-SELECT id, telegram_chat_id, context
+SELECT id, telegram_chat_id, context, time_zone, quiet_hours_start, quiet_hours_end, quiet_hours_policy
 FROM chats
 WHERE telegram_chat_id = $1
 AND deleted_at IS NULL;
@@ -14,3 +14,15 @@ UPDATE chats
 SET context = $1
 WHERE telegram_chat_id = $2
 RETURNING *;
+
+-- name: UpdateChatTimeZone :one
+UPDATE chats
+SET time_zone = $1
+WHERE telegram_chat_id = $2
+RETURNING *;
+
+-- name: UpdateChatQuietHours :one
+UPDATE chats
+SET quiet_hours_start = $1, quiet_hours_end = $2, quiet_hours_policy = $3
+WHERE telegram_chat_id = $4
+RETURNING *;
@@ -0,0 +1,108 @@
+-- name: CreateDelivery :one
+INSERT INTO deliveries (job_id, river_job_id, telegram_chat_id, fire_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (river_job_id) DO UPDATE
+SET river_job_id = EXCLUDED.river_job_id
+RETURNING *;
+
+-- name: CreateSnoozeDelivery :one
+INSERT INTO deliveries (job_id, river_job_id, telegram_chat_id, fire_at, snoozed_delivery_id)
+SELECT job_id, $1, telegram_chat_id, $2, id
+FROM deliveries
+WHERE id = $3
+ON CONFLICT (river_job_id) DO UPDATE
+SET river_job_id = EXCLUDED.river_job_id
+RETURNING *;
+
+-- name: UpdateDeliverySent :one
+WITH unsent AS (
+    SELECT id
+    FROM deliveries
+    WHERE id = $2
+    AND sent_at IS NULL
+    AND snoozed_delivery_id IS NULL
+    FOR UPDATE
+), sent AS (
+    UPDATE deliveries
+    SET telegram_message_id = $1, sent_at = NOW(), status = 'sent', error = NULL
+    WHERE id = $2
+    RETURNING *
+), counted AS (
+    UPDATE jobs
+    SET occurrence_count = occurrence_count + 1
+    FROM sent
+    JOIN unsent ON unsent.id = sent.id
+    WHERE jobs.id = sent.job_id
+)
+SELECT *
+FROM sent;
+
+-- name: UpdateDeliveryFailed :one
+UPDATE deliveries
+SET status = 'failed', error = $1
+WHERE id = $2
+RETURNING *;
+
+-- name: UpdateDeliveryDropped :one
+UPDATE deliveries
+SET status = 'dropped'
+WHERE id = $1
+AND sent_at IS NULL
+RETURNING *;
+
+-- name: DeferDelivery :one
+UPDATE deliveries
+SET deferred_until = $1
+WHERE id = $2
+AND sent_at IS NULL
+AND NOT EXISTS (SELECT 1
+                FROM deliveries AS deferred
+                WHERE deferred.job_id = deliveries.job_id
+                AND deferred.id <> deliveries.id
+                AND deferred.deferred_until = $1
+                AND deferred.snoozed_delivery_id IS NULL
+                AND deferred.deleted_at IS NULL)
+RETURNING *;
+
+-- name: IncrementDeliveryNagCount :one
+UPDATE deliveries
+SET nag_count = nag_count + 1
+WHERE id = $1
+RETURNING *;
+
+-- name: AcknowledgeDelivery :one
+UPDATE deliveries
+SET acknowledged_at = NOW()
+WHERE id = $1
+AND telegram_chat_id = $2
+AND acknowledged_at IS NULL
+RETURNING *;
+
+-- name: GetDeliveryNagPolicy :one
+SELECT deliveries.id, deliveries.job_id, deliveries.telegram_chat_id, deliveries.nag_count, deliveries.acknowledged_at,
+       jobs.is_recurring, jobs.message, jobs.nag_interval_minutes, jobs.nag_max_count, jobs.media_type,
+       jobs.media_file_id
+FROM deliveries
+JOIN jobs ON jobs.id = deliveries.job_id
+WHERE deliveries.id = $1
+AND deliveries.status <> 'dropped'
+AND deliveries.deleted_at IS NULL
+AND (jobs.deleted_at IS NULL OR jobs.is_recurring = false OR jobs.finished_at IS NOT NULL);
+
+-- name: GetDeliveryReminder :one
+SELECT jobs.message, jobs.media_type, jobs.media_file_id
+FROM deliveries
+JOIN jobs ON jobs.id = deliveries.job_id
+WHERE deliveries.id = $1
+AND deliveries.telegram_chat_id = $2;
+
+-- name: GetDeliveriesByTelegramChatID :many
+SELECT deliveries.id, deliveries.job_id, deliveries.telegram_message_id, deliveries.fire_at, deliveries.sent_at,
+       deliveries.acknowledged_at, deliveries.status, deliveries.error, jobs.name
+FROM deliveries
+LEFT JOIN jobs ON jobs.id = deliveries.job_id
+WHERE deliveries.telegram_chat_id = sqlc.arg('telegram_chat_id')
+AND (sqlc.narg('status')::TEXT IS NULL OR deliveries.status = sqlc.narg('status'))
+AND deliveries.deleted_at IS NULL
+ORDER BY deliveries.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
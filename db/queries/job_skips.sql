@@ -0,0 +1,20 @@
+-- name: CreateJobSkip :one
+INSERT INTO job_skips (job_id, fire_at, skip_date)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: IsJobOccurrenceSkipped :one
+SELECT EXISTS (SELECT 1
+               FROM job_skips
+               WHERE job_id = $1
+               AND deleted_at IS NULL
+               AND (fire_at = $2 OR skip_date = $3));
+
+-- name: GetUpcomingJobSkipsByTelegramChatID :many
+SELECT job_skips.id, job_skips.job_id, job_skips.fire_at, job_skips.skip_date
+FROM job_skips
+JOIN jobs ON jobs.id = job_skips.job_id AND jobs.deleted_at IS NULL
+WHERE jobs.telegram_chat_id = $1
+AND job_skips.deleted_at IS NULL
+AND (job_skips.fire_at > NOW() OR job_skips.skip_date >= $2)
+ORDER BY job_skips.fire_at, job_skips.skip_date;
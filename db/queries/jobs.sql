@@ -1,10 +1,13 @@
 -- name: CreateJob :one
-INSERT INTO jobs (telegram_chat_id, is_recurring, message, schedule, name, river_job_id)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO jobs (telegram_chat_id, is_recurring, message, schedule, name, river_job_id, ends_at, max_occurrences,
+                  interval_seconds, anchor_at, tags, media_type, media_file_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 RETURNING *;
 
 -- name: GetJobByID :one
-SELECT id, telegram_chat_id, is_recurring, message, schedule, name, river_job_id
+SELECT id, telegram_chat_id, is_recurring, message, schedule, name, river_job_id, paused_at, interval_seconds, anchor_at,
+       ends_at, max_occurrences, occurrence_count,
+       calendar_names, misfire_policy, media_type, media_file_id
 FROM jobs
 WHERE id = $1
 AND deleted_at IS NULL;
@@ -23,19 +26,161 @@ AND is_recurring = false
 RETURNING *;
 
 -- name: GetActiveRecurringJobs :many
-SELECT id, telegram_chat_id, is_recurring, message, schedule, name, river_job_id
+SELECT jobs.id, jobs.telegram_chat_id, jobs.is_recurring, jobs.message, jobs.schedule, jobs.name, jobs.river_job_id,
+       chats.time_zone
 FROM jobs
-WHERE is_recurring = true
-AND deleted_at IS NULL;
+JOIN chats ON chats.telegram_chat_id = jobs.telegram_chat_id AND chats.deleted_at IS NULL
+WHERE jobs.is_recurring = true
+AND jobs.deleted_at IS NULL
+AND jobs.paused_at IS NULL;
+
+-- name: GetActiveRecurringJobsByTelegramChatID :many
+SELECT jobs.id, jobs.telegram_chat_id, jobs.is_recurring, jobs.message, jobs.schedule, jobs.name, jobs.river_job_id,
+       chats.time_zone
+FROM jobs
+JOIN chats ON chats.telegram_chat_id = jobs.telegram_chat_id AND chats.deleted_at IS NULL
+WHERE jobs.telegram_chat_id = $1
+AND jobs.is_recurring = true
+AND jobs.deleted_at IS NULL
+AND jobs.paused_at IS NULL;
+
+-- name: GetActiveRecurringJobForUpdate :one
+SELECT jobs.id, jobs.telegram_chat_id, jobs.is_recurring, jobs.message, jobs.schedule, jobs.name, jobs.river_job_id,
+       chats.time_zone, jobs.ends_at, jobs.max_occurrences, jobs.finished_at, jobs.interval_seconds, jobs.anchor_at,
+       jobs.occurrence_count, jobs.calendar_names,
+       jobs.misfire_policy, jobs.last_fired_at, jobs.media_type, jobs.media_file_id
+FROM jobs
+JOIN chats ON chats.telegram_chat_id = jobs.telegram_chat_id AND chats.deleted_at IS NULL
+WHERE jobs.id = $1
+AND jobs.is_recurring = true
+AND jobs.deleted_at IS NULL
+AND jobs.paused_at IS NULL
+FOR UPDATE OF jobs;
 
 -- name: GetActiveJobsByTelegramChatID :many
-SELECT id, telegram_chat_id, is_recurring, message, schedule, name, river_job_id
+SELECT id, telegram_chat_id, is_recurring, message, schedule, name, river_job_id, paused_at, ends_at, max_occurrences,
+       interval_seconds, anchor_at, occurrence_count,
+       calendar_names, misfire_policy
 FROM jobs
 WHERE telegram_chat_id = $1
 AND deleted_at IS NULL;
 
+-- name: GetActiveJobsPageByTelegramChatID :many
+SELECT id, telegram_chat_id, is_recurring, message, schedule, name, river_job_id, paused_at, ends_at, max_occurrences,
+       interval_seconds, anchor_at, occurrence_count,
+       calendar_names, misfire_policy, tags, media_type
+FROM jobs
+WHERE telegram_chat_id = sqlc.arg('telegram_chat_id')
+AND deleted_at IS NULL
+AND (sqlc.narg('is_recurring')::BOOL IS NULL OR is_recurring = sqlc.narg('is_recurring'))
+AND (sqlc.narg('tag')::TEXT IS NULL OR sqlc.narg('tag') = ANY (tags))
+AND (sqlc.narg('fires_before')::TIMESTAMP IS NULL
+    OR (paused_at IS NULL
+        AND next_fire_at > sqlc.narg('fires_after')
+        AND next_fire_at < sqlc.narg('fires_before')
+        AND NOT calendar_names && sqlc.arg('excluded_calendar_names')::TEXT[]
+        AND NOT EXISTS (SELECT 1
+                        FROM job_skips
+                        WHERE job_skips.job_id = jobs.id
+                        AND job_skips.skip_date = sqlc.narg('fire_date')
+                        AND job_skips.deleted_at IS NULL)
+        AND NOT EXISTS (SELECT 1
+                        FROM calendars
+                        JOIN calendar_dates ON calendar_dates.calendar_id = calendars.id
+                            AND calendar_dates.deleted_at IS NULL
+                        WHERE calendars.telegram_chat_id = jobs.telegram_chat_id
+                        AND calendars.name = ANY (jobs.calendar_names)
+                        AND calendar_dates.date = sqlc.narg('fire_date')
+                        AND calendars.deleted_at IS NULL)))
+ORDER BY id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
 -- name: UpdateRiverJobID :one
 UPDATE jobs
-SET river_job_id = $1
+SET river_job_id = $1, next_fire_at = $2
+WHERE id = $3
+RETURNING *;
+
+-- name: UpdateJobNagPolicy :one
+UPDATE jobs
+SET nag_interval_minutes = $1, nag_max_count = $2
+WHERE id = $3
+RETURNING *;
+
+-- name: PauseJob :one
+UPDATE jobs
+SET paused_at = NOW(), next_fire_at = NULL
+WHERE id = $1
+AND is_recurring = true
+AND paused_at IS NULL
+AND deleted_at IS NULL
+RETURNING *;
+
+-- name: ResumeJob :one
+UPDATE jobs
+SET paused_at = NULL
+WHERE id = $1
+AND paused_at IS NOT NULL
+AND deleted_at IS NULL
+RETURNING *;
+
+-- name: FinishJob :one
+UPDATE jobs
+SET finished_at = NOW(), next_fire_at = NULL
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateJobDetails :one
+UPDATE jobs
+SET name = $1, message = $2, schedule = $3, interval_seconds = $4, anchor_at = $5
+WHERE id = $6
+AND deleted_at IS NULL
+RETURNING *;
+
+-- name: UpdateJobCalendars :one
+UPDATE jobs
+SET calendar_names = $1
+WHERE id = $2
+AND is_recurring = true
+AND deleted_at IS NULL
+RETURNING *;
+
+-- name: UpdateJobTags :one
+UPDATE jobs
+SET tags = $1
+WHERE id = $2
+AND deleted_at IS NULL
+RETURNING *;
+
+-- name: SearchJobsByTelegramChatID :many
+SELECT id, is_recurring, message, schedule, name, paused_at, tags
+FROM jobs
+WHERE telegram_chat_id = sqlc.arg('telegram_chat_id')
+AND deleted_at IS NULL
+AND (strpos(lower(name), lower(sqlc.arg('query')::TEXT)) > 0
+    OR strpos(lower(message), lower(sqlc.arg('query')::TEXT)) > 0
+    OR lower(sqlc.arg('query')::TEXT) = ANY (tags))
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: UpdateJobMisfirePolicy :one
+UPDATE jobs
+SET misfire_policy = $1
 WHERE id = $2
+AND deleted_at IS NULL
 RETURNING *;
+
+-- name: UpdateJobLastFiredAt :one
+UPDATE jobs
+SET last_fired_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetUnfiredScheduledJobs :many
+SELECT jobs.id, jobs.telegram_chat_id, jobs.message, jobs.schedule, jobs.name, jobs.river_job_id, jobs.misfire_policy,
+       chats.time_zone, jobs.media_type, jobs.media_file_id
+FROM jobs
+JOIN chats ON chats.telegram_chat_id = jobs.telegram_chat_id AND chats.deleted_at IS NULL
+WHERE jobs.is_recurring = false
+AND jobs.last_fired_at IS NULL
+AND jobs.deleted_at IS NULL;
@@ -0,0 +1,28 @@
+-- name: SaveTemplate :one
+INSERT INTO templates (telegram_chat_id, name, job_name, message, is_recurring, is_interval, media_type, media_file_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (telegram_chat_id, name) WHERE deleted_at IS NULL DO UPDATE
+SET job_name = EXCLUDED.job_name, message = EXCLUDED.message, is_recurring = EXCLUDED.is_recurring,
+    is_interval = EXCLUDED.is_interval, media_type = EXCLUDED.media_type, media_file_id = EXCLUDED.media_file_id
+RETURNING *;
+
+-- name: GetTemplateByName :one
+SELECT id, telegram_chat_id, name, job_name, message, is_recurring, is_interval, media_type, media_file_id
+FROM templates
+WHERE telegram_chat_id = $1
+AND name = $2
+AND deleted_at IS NULL;
+
+-- name: GetTemplatesByTelegramChatID :many
+SELECT id, name, job_name, message, is_recurring, is_interval, media_type
+FROM templates
+WHERE telegram_chat_id = $1
+AND deleted_at IS NULL
+ORDER BY name;
+
+-- name: DeleteTemplate :execrows
+UPDATE templates
+SET deleted_at = NOW()
+WHERE telegram_chat_id = $1
+AND name = $2
+AND deleted_at IS NULL;
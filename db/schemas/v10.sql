@@ -0,0 +1,38 @@
+CREATE TABLE calendars
+(
+    id               SERIAL PRIMARY KEY,
+    telegram_chat_id BIGINT       NOT NULL,
+    name             VARCHAR(191) NOT NULL,
+    created_at       TIMESTAMP DEFAULT current_timestamp,
+    updated_at       TIMESTAMP DEFAULT NULL,
+    deleted_at       TIMESTAMP DEFAULT NULL
+);
+
+CREATE TRIGGER update_updated_at
+    BEFORE UPDATE
+    ON calendars
+    FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
+
+CREATE UNIQUE INDEX calendars_telegram_chat_id_name_idx ON calendars (telegram_chat_id, name) WHERE deleted_at IS NULL;
+
+CREATE TABLE calendar_dates
+(
+    id          SERIAL PRIMARY KEY,
+    calendar_id INT  NOT NULL,
+    date        DATE NOT NULL,
+    created_at  TIMESTAMP DEFAULT current_timestamp,
+    updated_at  TIMESTAMP DEFAULT NULL,
+    deleted_at  TIMESTAMP DEFAULT NULL
+);
+
+CREATE TRIGGER update_updated_at
+    BEFORE UPDATE
+    ON calendar_dates
+    FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
+
+CREATE UNIQUE INDEX calendar_dates_calendar_id_date_idx ON calendar_dates (calendar_id, date);
+
+ALTER TABLE jobs
+    ADD COLUMN calendar_names TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,3 @@
+ALTER TABLE jobs
+    ADD COLUMN misfire_policy VARCHAR(191) NOT NULL DEFAULT 'once',
+    ADD COLUMN last_fired_at  TIMESTAMP             DEFAULT NULL;
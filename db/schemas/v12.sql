@@ -0,0 +1,21 @@
+CREATE TABLE templates
+(
+    id               SERIAL PRIMARY KEY,
+    telegram_chat_id BIGINT       NOT NULL,
+    name             VARCHAR(191) NOT NULL,
+    job_name         VARCHAR(191) NOT NULL COLLATE "unicode",
+    message          TEXT         NOT NULL,
+    is_recurring     BOOL      DEFAULT NULL,
+    is_interval      BOOL         NOT NULL DEFAULT false,
+    created_at       TIMESTAMP DEFAULT current_timestamp,
+    updated_at       TIMESTAMP DEFAULT NULL,
+    deleted_at       TIMESTAMP DEFAULT NULL
+);
+
+CREATE TRIGGER update_updated_at
+    BEFORE UPDATE
+    ON templates
+    FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
+
+CREATE UNIQUE INDEX templates_telegram_chat_id_name_idx ON templates (telegram_chat_id, name) WHERE deleted_at IS NULL;
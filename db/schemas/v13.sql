@@ -0,0 +1,14 @@
+ALTER TABLE jobs
+    ADD COLUMN tags         TEXT[]    NOT NULL DEFAULT '{}',
+    ADD COLUMN next_fire_at TIMESTAMP DEFAULT NULL;
+
+CREATE INDEX jobs_tags_idx ON jobs USING GIN (tags);
+
+UPDATE jobs
+SET next_fire_at = river_job.scheduled_at AT TIME ZONE 'UTC'
+FROM river_job
+WHERE river_job.id = jobs.river_job_id
+AND river_job.state IN ('available', 'pending', 'retryable', 'running', 'scheduled')
+AND jobs.paused_at IS NULL
+AND jobs.finished_at IS NULL
+AND jobs.deleted_at IS NULL;
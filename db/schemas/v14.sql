@@ -0,0 +1,7 @@
+ALTER TABLE jobs
+    ADD COLUMN media_type    VARCHAR(191) NOT NULL DEFAULT '',
+    ADD COLUMN media_file_id TEXT         NOT NULL DEFAULT '';
+
+ALTER TABLE templates
+    ADD COLUMN media_type    VARCHAR(191) NOT NULL DEFAULT '',
+    ADD COLUMN media_file_id TEXT         NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE chats
+    ADD COLUMN time_zone VARCHAR(191) NOT NULL DEFAULT 'UTC';
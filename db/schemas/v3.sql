@@ -0,0 +1,27 @@
+ALTER TABLE jobs
+    ADD COLUMN nag_interval_minutes INT DEFAULT NULL,
+    ADD COLUMN nag_max_count        INT DEFAULT NULL;
+
+CREATE TABLE deliveries
+(
+    id                  SERIAL PRIMARY KEY,
+    job_id              INT,
+    river_job_id        BIGINT NOT NULL,
+    telegram_chat_id    BIGINT NOT NULL,
+    telegram_message_id BIGINT,
+    nag_count           INT    NOT NULL DEFAULT 0,
+    sent_at             TIMESTAMP DEFAULT NULL,
+    acknowledged_at     TIMESTAMP DEFAULT NULL,
+    created_at          TIMESTAMP DEFAULT current_timestamp,
+    updated_at          TIMESTAMP DEFAULT NULL,
+    deleted_at          TIMESTAMP DEFAULT NULL
+);
+
+CREATE TRIGGER update_updated_at
+    BEFORE UPDATE
+    ON deliveries
+    FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
+
+CREATE UNIQUE INDEX deliveries_river_job_id_idx ON deliveries (river_job_id);
+CREATE INDEX deliveries_chat_id_idx ON deliveries (telegram_chat_id);
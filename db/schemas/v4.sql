@@ -0,0 +1,5 @@
+ALTER TABLE deliveries
+    ADD COLUMN fire_at             TIMESTAMP    DEFAULT NULL,
+    ADD COLUMN status              VARCHAR(191) NOT NULL DEFAULT 'pending',
+    ADD COLUMN error               TEXT         DEFAULT NULL,
+    ADD COLUMN snoozed_delivery_id INT          DEFAULT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE jobs
+    ADD COLUMN paused_at TIMESTAMP DEFAULT NULL;
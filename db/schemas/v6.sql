@@ -0,0 +1,12 @@
+ALTER TABLE jobs
+    ADD COLUMN ends_at          TIMESTAMP DEFAULT NULL,
+    ADD COLUMN max_occurrences  INT       DEFAULT NULL,
+    ADD COLUMN finished_at      TIMESTAMP DEFAULT NULL,
+    ADD COLUMN occurrence_count BIGINT    NOT NULL DEFAULT 0;
+
+UPDATE jobs
+SET occurrence_count = (SELECT COUNT(*)
+                        FROM deliveries
+                        WHERE deliveries.job_id = jobs.id
+                        AND deliveries.status = 'sent'
+                        AND deliveries.snoozed_delivery_id IS NULL);
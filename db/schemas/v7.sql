@@ -0,0 +1,18 @@
+CREATE TABLE job_skips
+(
+    id         SERIAL PRIMARY KEY,
+    job_id     INT NOT NULL,
+    fire_at    TIMESTAMP DEFAULT NULL,
+    skip_date  DATE      DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT current_timestamp,
+    updated_at TIMESTAMP DEFAULT NULL,
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE TRIGGER update_updated_at
+    BEFORE UPDATE
+    ON job_skips
+    FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
+
+CREATE INDEX job_skips_job_id_idx ON job_skips (job_id);
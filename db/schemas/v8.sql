@@ -0,0 +1,3 @@
+ALTER TABLE jobs
+    ADD COLUMN interval_seconds INT       DEFAULT NULL,
+    ADD COLUMN anchor_at        TIMESTAMP DEFAULT NULL;
@@ -0,0 +1,7 @@
+ALTER TABLE chats
+    ADD COLUMN quiet_hours_start  INT          DEFAULT NULL,
+    ADD COLUMN quiet_hours_end    INT          DEFAULT NULL,
+    ADD COLUMN quiet_hours_policy VARCHAR(191) NOT NULL DEFAULT 'defer';
+
+ALTER TABLE deliveries
+    ADD COLUMN deferred_until TIMESTAMP DEFAULT NULL;
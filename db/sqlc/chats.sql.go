@@ -7,12 +7,14 @@ package sqlc
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createChat = `-- name: CreateChat :one
 INSERT INTO chats (telegram_chat_id)
 VALUES ($1)
-RETURNING id, telegram_chat_id, context, created_at, updated_at, deleted_at
+RETURNING id, telegram_chat_id, context, created_at, updated_at, deleted_at, time_zone, quiet_hours_start, quiet_hours_end, quiet_hours_policy
 `
 
 func (q *Queries) CreateChat(ctx context.Context, telegramChatID int64) (Chat, error) {
@@ -25,27 +27,43 @@ func (q *Queries) CreateChat(ctx context.Context, telegramChatID int64) (Chat, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.TimeZone,
+		&i.QuietHoursStart,
+		&i.QuietHoursEnd,
+		&i.QuietHoursPolicy,
 	)
 	return i, err
 }
 
 const getChat = `-- name: GetChat :one
-SELECT id, telegram_chat_id, context
+SELECT id, telegram_chat_id, context, time_zone, quiet_hours_start, quiet_hours_end, quiet_hours_policy
 FROM chats
 WHERE telegram_chat_id = $1
 AND deleted_at IS NULL
 `
 
 type GetChatRow struct {
-	ID             int32
-	TelegramChatID int64
-	Context        []byte
+	ID               int32
+	TelegramChatID   int64
+	Context          []byte
+	TimeZone         string
+	QuietHoursStart  pgtype.Int4
+	QuietHoursEnd    pgtype.Int4
+	QuietHoursPolicy string
 }
 
 func (q *Queries) GetChat(ctx context.Context, telegramChatID int64) (GetChatRow, error) {
 	row := q.db.QueryRow(ctx, getChat, telegramChatID)
 	var i GetChatRow
-	err := row.Scan(&i.ID, &i.TelegramChatID, &i.Context)
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramChatID,
+		&i.Context,
+		&i.TimeZone,
+		&i.QuietHoursStart,
+		&i.QuietHoursEnd,
+		&i.QuietHoursPolicy,
+	)
 	return i, err
 }
 
@@ -53,7 +71,7 @@ const updateChatContext = `-- name: UpdateChatContext :one
 UPDATE chats
 SET context = $1
 WHERE telegram_chat_id = $2
-RETURNING id, telegram_chat_id, context, created_at, updated_at, deleted_at
+RETURNING id, telegram_chat_id, context, created_at, updated_at, deleted_at, time_zone, quiet_hours_start, quiet_hours_end, quiet_hours_policy
 `
 
 type UpdateChatContextParams struct {
@@ -71,6 +89,77 @@ func (q *Queries) UpdateChatContext(ctx context.Context, arg UpdateChatContextPa
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.TimeZone,
+		&i.QuietHoursStart,
+		&i.QuietHoursEnd,
+		&i.QuietHoursPolicy,
+	)
+	return i, err
+}
+
+const updateChatQuietHours = `-- name: UpdateChatQuietHours :one
+UPDATE chats
+SET quiet_hours_start = $1, quiet_hours_end = $2, quiet_hours_policy = $3
+WHERE telegram_chat_id = $4
+RETURNING id, telegram_chat_id, context, created_at, updated_at, deleted_at, time_zone, quiet_hours_start, quiet_hours_end, quiet_hours_policy
+`
+
+type UpdateChatQuietHoursParams struct {
+	QuietHoursStart  pgtype.Int4
+	QuietHoursEnd    pgtype.Int4
+	QuietHoursPolicy string
+	TelegramChatID   int64
+}
+
+func (q *Queries) UpdateChatQuietHours(ctx context.Context, arg UpdateChatQuietHoursParams) (Chat, error) {
+	row := q.db.QueryRow(ctx, updateChatQuietHours,
+		arg.QuietHoursStart,
+		arg.QuietHoursEnd,
+		arg.QuietHoursPolicy,
+		arg.TelegramChatID,
+	)
+	var i Chat
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramChatID,
+		&i.Context,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TimeZone,
+		&i.QuietHoursStart,
+		&i.QuietHoursEnd,
+		&i.QuietHoursPolicy,
+	)
+	return i, err
+}
+
+const updateChatTimeZone = `-- name: UpdateChatTimeZone :one
+UPDATE chats
+SET time_zone = $1
+WHERE telegram_chat_id = $2
+RETURNING id, telegram_chat_id, context, created_at, updated_at, deleted_at, time_zone, quiet_hours_start, quiet_hours_end, quiet_hours_policy
+`
+
+type UpdateChatTimeZoneParams struct {
+	TimeZone       string
+	TelegramChatID int64
+}
+
+func (q *Queries) UpdateChatTimeZone(ctx context.Context, arg UpdateChatTimeZoneParams) (Chat, error) {
+	row := q.db.QueryRow(ctx, updateChatTimeZone, arg.TimeZone, arg.TelegramChatID)
+	var i Chat
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramChatID,
+		&i.Context,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TimeZone,
+		&i.QuietHoursStart,
+		&i.QuietHoursEnd,
+		&i.QuietHoursPolicy,
 	)
 	return i, err
 }
@@ -12,18 +12,26 @@ import (
 )
 
 const createJob = `-- name: CreateJob :one
-INSERT INTO jobs (telegram_chat_id, is_recurring, message, schedule, name, river_job_id)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, telegram_chat_id, is_recurring, river_job_id, message, schedule, name, created_at, updated_at, deleted_at
+INSERT INTO jobs (telegram_chat_id, is_recurring, message, schedule, name, river_job_id, ends_at, max_occurrences,
+                  interval_seconds, anchor_at, tags, media_type, media_file_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+RETURNING id, telegram_chat_id, is_recurring, river_job_id, message, schedule, name, created_at, updated_at, deleted_at, nag_interval_minutes, nag_max_count, paused_at, ends_at, max_occurrences, finished_at, occurrence_count, interval_seconds, anchor_at, calendar_names, misfire_policy, last_fired_at, tags, next_fire_at, media_type, media_file_id
 `
 
 type CreateJobParams struct {
-	TelegramChatID int64
-	IsRecurring    bool
-	Message        string
-	Schedule       string
-	Name           string
-	RiverJobID     pgtype.Int8
+	TelegramChatID  int64
+	IsRecurring     bool
+	Message         string
+	Schedule        string
+	Name            string
+	RiverJobID      pgtype.Int8
+	EndsAt          pgtype.Timestamp
+	MaxOccurrences  pgtype.Int4
+	IntervalSeconds pgtype.Int4
+	AnchorAt        pgtype.Timestamp
+	Tags            []string
+	MediaType       string
+	MediaFileID     string
 }
 
 func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, error) {
@@ -34,6 +42,13 @@ func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, erro
 		arg.Schedule,
 		arg.Name,
 		arg.RiverJobID,
+		arg.EndsAt,
+		arg.MaxOccurrences,
+		arg.IntervalSeconds,
+		arg.AnchorAt,
+		arg.Tags,
+		arg.MediaType,
+		arg.MediaFileID,
 	)
 	var i Job
 	err := row.Scan(
@@ -47,6 +62,22 @@ func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, erro
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.NagIntervalMinutes,
+		&i.NagMaxCount,
+		&i.PausedAt,
+		&i.EndsAt,
+		&i.MaxOccurrences,
+		&i.FinishedAt,
+		&i.OccurrenceCount,
+		&i.IntervalSeconds,
+		&i.AnchorAt,
+		&i.CalendarNames,
+		&i.MisfirePolicy,
+		&i.LastFiredAt,
+		&i.Tags,
+		&i.NextFireAt,
+		&i.MediaType,
+		&i.MediaFileID,
 	)
 	return i, err
 }
@@ -55,7 +86,7 @@ const deleteJobByID = `-- name: DeleteJobByID :one
 UPDATE jobs
 SET deleted_at = NOW()
 WHERE id = $1
-RETURNING id, telegram_chat_id, is_recurring, river_job_id, message, schedule, name, created_at, updated_at, deleted_at
+RETURNING id, telegram_chat_id, is_recurring, river_job_id, message, schedule, name, created_at, updated_at, deleted_at, nag_interval_minutes, nag_max_count, paused_at, ends_at, max_occurrences, finished_at, occurrence_count, interval_seconds, anchor_at, calendar_names, misfire_policy, last_fired_at, tags, next_fire_at, media_type, media_file_id
 `
 
 func (q *Queries) DeleteJobByID(ctx context.Context, id int32) (Job, error) {
@@ -72,6 +103,22 @@ func (q *Queries) DeleteJobByID(ctx context.Context, id int32) (Job, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.NagIntervalMinutes,
+		&i.NagMaxCount,
+		&i.PausedAt,
+		&i.EndsAt,
+		&i.MaxOccurrences,
+		&i.FinishedAt,
+		&i.OccurrenceCount,
+		&i.IntervalSeconds,
+		&i.AnchorAt,
+		&i.CalendarNames,
+		&i.MisfirePolicy,
+		&i.LastFiredAt,
+		&i.Tags,
+		&i.NextFireAt,
+		&i.MediaType,
+		&i.MediaFileID,
 	)
 	return i, err
 }
@@ -81,7 +128,7 @@ UPDATE jobs
 SET deleted_at = NOW()
 WHERE river_job_id = $1
 AND is_recurring = false
-RETURNING id, telegram_chat_id, is_recurring, river_job_id, message, schedule, name, created_at, updated_at, deleted_at
+RETURNING id, telegram_chat_id, is_recurring, river_job_id, message, schedule, name, created_at, updated_at, deleted_at, nag_interval_minutes, nag_max_count, paused_at, ends_at, max_occurrences, finished_at, occurrence_count, interval_seconds, anchor_at, calendar_names, misfire_policy, last_fired_at, tags, next_fire_at, media_type, media_file_id
 `
 
 func (q *Queries) DeleteScheduledJobByRiverJobID(ctx context.Context, riverJobID pgtype.Int8) (Job, error) {
@@ -98,25 +145,92 @@ func (q *Queries) DeleteScheduledJobByRiverJobID(ctx context.Context, riverJobID
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.NagIntervalMinutes,
+		&i.NagMaxCount,
+		&i.PausedAt,
+		&i.EndsAt,
+		&i.MaxOccurrences,
+		&i.FinishedAt,
+		&i.OccurrenceCount,
+		&i.IntervalSeconds,
+		&i.AnchorAt,
+		&i.CalendarNames,
+		&i.MisfirePolicy,
+		&i.LastFiredAt,
+		&i.Tags,
+		&i.NextFireAt,
+		&i.MediaType,
+		&i.MediaFileID,
+	)
+	return i, err
+}
+
+const finishJob = `-- name: FinishJob :one
+UPDATE jobs
+SET finished_at = NOW(), next_fire_at = NULL
+WHERE id = $1
+RETURNING id, telegram_chat_id, is_recurring, river_job_id, message, schedule, name, created_at, updated_at, deleted_at, nag_interval_minutes, nag_max_count, paused_at, ends_at, max_occurrences, finished_at, occurrence_count, interval_seconds, anchor_at, calendar_names, misfire_policy, last_fired_at, tags, next_fire_at, media_type, media_file_id
+`
+
+func (q *Queries) FinishJob(ctx context.Context, id int32) (Job, error) {
+	row := q.db.QueryRow(ctx, finishJob, id)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramChatID,
+		&i.IsRecurring,
+		&i.RiverJobID,
+		&i.Message,
+		&i.Schedule,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.NagIntervalMinutes,
+		&i.NagMaxCount,
+		&i.PausedAt,
+		&i.EndsAt,
+		&i.MaxOccurrences,
+		&i.FinishedAt,
+		&i.OccurrenceCount,
+		&i.IntervalSeconds,
+		&i.AnchorAt,
+		&i.CalendarNames,
+		&i.MisfirePolicy,
+		&i.LastFiredAt,
+		&i.Tags,
+		&i.NextFireAt,
+		&i.MediaType,
+		&i.MediaFileID,
 	)
 	return i, err
 }
 
 const getActiveJobsByTelegramChatID = `-- name: GetActiveJobsByTelegramChatID :many
-SELECT id, telegram_chat_id, is_recurring, message, schedule, name, river_job_id
+SELECT id, telegram_chat_id, is_recurring, message, schedule, name, river_job_id, paused_at, ends_at, max_occurrences,
+       interval_seconds, anchor_at, occurrence_count,
+       calendar_names, misfire_policy
 FROM jobs
 WHERE telegram_chat_id = $1
 AND deleted_at IS NULL
 `
 
 type GetActiveJobsByTelegramChatIDRow struct {
-	ID             int32
-	TelegramChatID int64
-	IsRecurring    bool
-	Message        string
-	Schedule       string
-	Name           string
-	RiverJobID     pgtype.Int8
+	ID              int32
+	TelegramChatID  int64
+	IsRecurring     bool
+	Message         string
+	Schedule        string
+	Name            string
+	RiverJobID      pgtype.Int8
+	PausedAt        pgtype.Timestamp
+	EndsAt          pgtype.Timestamp
+	MaxOccurrences  pgtype.Int4
+	IntervalSeconds pgtype.Int4
+	AnchorAt        pgtype.Timestamp
+	OccurrenceCount int64
+	CalendarNames   []string
+	MisfirePolicy   string
 }
 
 func (q *Queries) GetActiveJobsByTelegramChatID(ctx context.Context, telegramChatID int64) ([]GetActiveJobsByTelegramChatIDRow, error) {
@@ -136,6 +250,14 @@ func (q *Queries) GetActiveJobsByTelegramChatID(ctx context.Context, telegramCha
 			&i.Schedule,
 			&i.Name,
 			&i.RiverJobID,
+			&i.PausedAt,
+			&i.EndsAt,
+			&i.MaxOccurrences,
+			&i.IntervalSeconds,
+			&i.AnchorAt,
+			&i.OccurrenceCount,
+			&i.CalendarNames,
+			&i.MisfirePolicy,
 		); err != nil {
 			return nil, err
 		}
@@ -147,11 +269,188 @@ func (q *Queries) GetActiveJobsByTelegramChatID(ctx context.Context, telegramCha
 	return items, nil
 }
 
-const getActiveRecurringJobs = `-- name: GetActiveRecurringJobs :many
-SELECT id, telegram_chat_id, is_recurring, message, schedule, name, river_job_id
+const getActiveJobsPageByTelegramChatID = `-- name: GetActiveJobsPageByTelegramChatID :many
+SELECT id, telegram_chat_id, is_recurring, message, schedule, name, river_job_id, paused_at, ends_at, max_occurrences,
+       interval_seconds, anchor_at, occurrence_count,
+       calendar_names, misfire_policy, tags, media_type
 FROM jobs
-WHERE is_recurring = true
+WHERE telegram_chat_id = $1
 AND deleted_at IS NULL
+AND ($2::BOOL IS NULL OR is_recurring = $2)
+AND ($3::TEXT IS NULL OR $3 = ANY (tags))
+AND ($4::TIMESTAMP IS NULL
+    OR (paused_at IS NULL
+        AND next_fire_at > $5
+        AND next_fire_at < $4
+        AND NOT calendar_names && $6::TEXT[]
+        AND NOT EXISTS (SELECT 1
+                        FROM job_skips
+                        WHERE job_skips.job_id = jobs.id
+                        AND job_skips.skip_date = $7
+                        AND job_skips.deleted_at IS NULL)
+        AND NOT EXISTS (SELECT 1
+                        FROM calendars
+                        JOIN calendar_dates ON calendar_dates.calendar_id = calendars.id
+                            AND calendar_dates.deleted_at IS NULL
+                        WHERE calendars.telegram_chat_id = jobs.telegram_chat_id
+                        AND calendars.name = ANY (jobs.calendar_names)
+                        AND calendar_dates.date = $7
+                        AND calendars.deleted_at IS NULL)))
+ORDER BY id
+LIMIT $8 OFFSET $9
+`
+
+type GetActiveJobsPageByTelegramChatIDParams struct {
+	TelegramChatID        int64
+	IsRecurring           pgtype.Bool
+	Tag                   pgtype.Text
+	FiresBefore           pgtype.Timestamp
+	FiresAfter            pgtype.Timestamp
+	ExcludedCalendarNames []string
+	FireDate              pgtype.Date
+	Limit                 int32
+	Offset                int32
+}
+
+type GetActiveJobsPageByTelegramChatIDRow struct {
+	ID              int32
+	TelegramChatID  int64
+	IsRecurring     bool
+	Message         string
+	Schedule        string
+	Name            string
+	RiverJobID      pgtype.Int8
+	PausedAt        pgtype.Timestamp
+	EndsAt          pgtype.Timestamp
+	MaxOccurrences  pgtype.Int4
+	IntervalSeconds pgtype.Int4
+	AnchorAt        pgtype.Timestamp
+	OccurrenceCount int64
+	CalendarNames   []string
+	MisfirePolicy   string
+	Tags            []string
+	MediaType       string
+}
+
+func (q *Queries) GetActiveJobsPageByTelegramChatID(ctx context.Context, arg GetActiveJobsPageByTelegramChatIDParams) ([]GetActiveJobsPageByTelegramChatIDRow, error) {
+	rows, err := q.db.Query(ctx, getActiveJobsPageByTelegramChatID,
+		arg.TelegramChatID,
+		arg.IsRecurring,
+		arg.Tag,
+		arg.FiresBefore,
+		arg.FiresAfter,
+		arg.ExcludedCalendarNames,
+		arg.FireDate,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetActiveJobsPageByTelegramChatIDRow
+	for rows.Next() {
+		var i GetActiveJobsPageByTelegramChatIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TelegramChatID,
+			&i.IsRecurring,
+			&i.Message,
+			&i.Schedule,
+			&i.Name,
+			&i.RiverJobID,
+			&i.PausedAt,
+			&i.EndsAt,
+			&i.MaxOccurrences,
+			&i.IntervalSeconds,
+			&i.AnchorAt,
+			&i.OccurrenceCount,
+			&i.CalendarNames,
+			&i.MisfirePolicy,
+			&i.Tags,
+			&i.MediaType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getActiveRecurringJobForUpdate = `-- name: GetActiveRecurringJobForUpdate :one
+SELECT jobs.id, jobs.telegram_chat_id, jobs.is_recurring, jobs.message, jobs.schedule, jobs.name, jobs.river_job_id,
+       chats.time_zone, jobs.ends_at, jobs.max_occurrences, jobs.finished_at, jobs.interval_seconds, jobs.anchor_at,
+       jobs.occurrence_count, jobs.calendar_names,
+       jobs.misfire_policy, jobs.last_fired_at, jobs.media_type, jobs.media_file_id
+FROM jobs
+JOIN chats ON chats.telegram_chat_id = jobs.telegram_chat_id AND chats.deleted_at IS NULL
+WHERE jobs.id = $1
+AND jobs.is_recurring = true
+AND jobs.deleted_at IS NULL
+AND jobs.paused_at IS NULL
+FOR UPDATE OF jobs
+`
+
+type GetActiveRecurringJobForUpdateRow struct {
+	ID              int32
+	TelegramChatID  int64
+	IsRecurring     bool
+	Message         string
+	Schedule        string
+	Name            string
+	RiverJobID      pgtype.Int8
+	TimeZone        string
+	EndsAt          pgtype.Timestamp
+	MaxOccurrences  pgtype.Int4
+	FinishedAt      pgtype.Timestamp
+	IntervalSeconds pgtype.Int4
+	AnchorAt        pgtype.Timestamp
+	OccurrenceCount int64
+	CalendarNames   []string
+	MisfirePolicy   string
+	LastFiredAt     pgtype.Timestamp
+	MediaType       string
+	MediaFileID     string
+}
+
+func (q *Queries) GetActiveRecurringJobForUpdate(ctx context.Context, id int32) (GetActiveRecurringJobForUpdateRow, error) {
+	row := q.db.QueryRow(ctx, getActiveRecurringJobForUpdate, id)
+	var i GetActiveRecurringJobForUpdateRow
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramChatID,
+		&i.IsRecurring,
+		&i.Message,
+		&i.Schedule,
+		&i.Name,
+		&i.RiverJobID,
+		&i.TimeZone,
+		&i.EndsAt,
+		&i.MaxOccurrences,
+		&i.FinishedAt,
+		&i.IntervalSeconds,
+		&i.AnchorAt,
+		&i.OccurrenceCount,
+		&i.CalendarNames,
+		&i.MisfirePolicy,
+		&i.LastFiredAt,
+		&i.MediaType,
+		&i.MediaFileID,
+	)
+	return i, err
+}
+
+const getActiveRecurringJobs = `-- name: GetActiveRecurringJobs :many
+SELECT jobs.id, jobs.telegram_chat_id, jobs.is_recurring, jobs.message, jobs.schedule, jobs.name, jobs.river_job_id,
+       chats.time_zone
+FROM jobs
+JOIN chats ON chats.telegram_chat_id = jobs.telegram_chat_id AND chats.deleted_at IS NULL
+WHERE jobs.is_recurring = true
+AND jobs.deleted_at IS NULL
+AND jobs.paused_at IS NULL
 `
 
 type GetActiveRecurringJobsRow struct {
@@ -162,6 +461,7 @@ type GetActiveRecurringJobsRow struct {
 	Schedule       string
 	Name           string
 	RiverJobID     pgtype.Int8
+	TimeZone       string
 }
 
 func (q *Queries) GetActiveRecurringJobs(ctx context.Context) ([]GetActiveRecurringJobsRow, error) {
@@ -181,6 +481,7 @@ func (q *Queries) GetActiveRecurringJobs(ctx context.Context) ([]GetActiveRecurr
 			&i.Schedule,
 			&i.Name,
 			&i.RiverJobID,
+			&i.TimeZone,
 		); err != nil {
 			return nil, err
 		}
@@ -192,14 +493,18 @@ func (q *Queries) GetActiveRecurringJobs(ctx context.Context) ([]GetActiveRecurr
 	return items, nil
 }
 
-const getJobByID = `-- name: GetJobByID :one
-SELECT id, telegram_chat_id, is_recurring, message, schedule, name, river_job_id
+const getActiveRecurringJobsByTelegramChatID = `-- name: GetActiveRecurringJobsByTelegramChatID :many
+SELECT jobs.id, jobs.telegram_chat_id, jobs.is_recurring, jobs.message, jobs.schedule, jobs.name, jobs.river_job_id,
+       chats.time_zone
 FROM jobs
-WHERE id = $1
-AND deleted_at IS NULL
+JOIN chats ON chats.telegram_chat_id = jobs.telegram_chat_id AND chats.deleted_at IS NULL
+WHERE jobs.telegram_chat_id = $1
+AND jobs.is_recurring = true
+AND jobs.deleted_at IS NULL
+AND jobs.paused_at IS NULL
 `
 
-type GetJobByIDRow struct {
+type GetActiveRecurringJobsByTelegramChatIDRow struct {
 	ID             int32
 	TelegramChatID int64
 	IsRecurring    bool
@@ -207,6 +512,65 @@ type GetJobByIDRow struct {
 	Schedule       string
 	Name           string
 	RiverJobID     pgtype.Int8
+	TimeZone       string
+}
+
+func (q *Queries) GetActiveRecurringJobsByTelegramChatID(ctx context.Context, telegramChatID int64) ([]GetActiveRecurringJobsByTelegramChatIDRow, error) {
+	rows, err := q.db.Query(ctx, getActiveRecurringJobsByTelegramChatID, telegramChatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetActiveRecurringJobsByTelegramChatIDRow
+	for rows.Next() {
+		var i GetActiveRecurringJobsByTelegramChatIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TelegramChatID,
+			&i.IsRecurring,
+			&i.Message,
+			&i.Schedule,
+			&i.Name,
+			&i.RiverJobID,
+			&i.TimeZone,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getJobByID = `-- name: GetJobByID :one
+SELECT id, telegram_chat_id, is_recurring, message, schedule, name, river_job_id, paused_at, interval_seconds, anchor_at,
+       ends_at, max_occurrences, occurrence_count,
+       calendar_names, misfire_policy, media_type, media_file_id
+FROM jobs
+WHERE id = $1
+AND deleted_at IS NULL
+`
+
+type GetJobByIDRow struct {
+	ID              int32
+	TelegramChatID  int64
+	IsRecurring     bool
+	Message         string
+	Schedule        string
+	Name            string
+	RiverJobID      pgtype.Int8
+	PausedAt        pgtype.Timestamp
+	IntervalSeconds pgtype.Int4
+	AnchorAt        pgtype.Timestamp
+	EndsAt          pgtype.Timestamp
+	MaxOccurrences  pgtype.Int4
+	OccurrenceCount int64
+	CalendarNames   []string
+	MisfirePolicy   string
+	MediaType       string
+	MediaFileID     string
 }
 
 func (q *Queries) GetJobByID(ctx context.Context, id int32) (GetJobByIDRow, error) {
@@ -220,24 +584,520 @@ func (q *Queries) GetJobByID(ctx context.Context, id int32) (GetJobByIDRow, erro
 		&i.Schedule,
 		&i.Name,
 		&i.RiverJobID,
+		&i.PausedAt,
+		&i.IntervalSeconds,
+		&i.AnchorAt,
+		&i.EndsAt,
+		&i.MaxOccurrences,
+		&i.OccurrenceCount,
+		&i.CalendarNames,
+		&i.MisfirePolicy,
+		&i.MediaType,
+		&i.MediaFileID,
 	)
 	return i, err
 }
 
-const updateRiverJobID = `-- name: UpdateRiverJobID :one
+const getUnfiredScheduledJobs = `-- name: GetUnfiredScheduledJobs :many
+SELECT jobs.id, jobs.telegram_chat_id, jobs.message, jobs.schedule, jobs.name, jobs.river_job_id, jobs.misfire_policy,
+       chats.time_zone, jobs.media_type, jobs.media_file_id
+FROM jobs
+JOIN chats ON chats.telegram_chat_id = jobs.telegram_chat_id AND chats.deleted_at IS NULL
+WHERE jobs.is_recurring = false
+AND jobs.last_fired_at IS NULL
+AND jobs.deleted_at IS NULL
+`
+
+type GetUnfiredScheduledJobsRow struct {
+	ID             int32
+	TelegramChatID int64
+	Message        string
+	Schedule       string
+	Name           string
+	RiverJobID     pgtype.Int8
+	MisfirePolicy  string
+	TimeZone       string
+	MediaType      string
+	MediaFileID    string
+}
+
+func (q *Queries) GetUnfiredScheduledJobs(ctx context.Context) ([]GetUnfiredScheduledJobsRow, error) {
+	rows, err := q.db.Query(ctx, getUnfiredScheduledJobs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUnfiredScheduledJobsRow
+	for rows.Next() {
+		var i GetUnfiredScheduledJobsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TelegramChatID,
+			&i.Message,
+			&i.Schedule,
+			&i.Name,
+			&i.RiverJobID,
+			&i.MisfirePolicy,
+			&i.TimeZone,
+			&i.MediaType,
+			&i.MediaFileID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const pauseJob = `-- name: PauseJob :one
+UPDATE jobs
+SET paused_at = NOW(), next_fire_at = NULL
+WHERE id = $1
+AND is_recurring = true
+AND paused_at IS NULL
+AND deleted_at IS NULL
+RETURNING id, telegram_chat_id, is_recurring, river_job_id, message, schedule, name, created_at, updated_at, deleted_at, nag_interval_minutes, nag_max_count, paused_at, ends_at, max_occurrences, finished_at, occurrence_count, interval_seconds, anchor_at, calendar_names, misfire_policy, last_fired_at, tags, next_fire_at, media_type, media_file_id
+`
+
+func (q *Queries) PauseJob(ctx context.Context, id int32) (Job, error) {
+	row := q.db.QueryRow(ctx, pauseJob, id)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramChatID,
+		&i.IsRecurring,
+		&i.RiverJobID,
+		&i.Message,
+		&i.Schedule,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.NagIntervalMinutes,
+		&i.NagMaxCount,
+		&i.PausedAt,
+		&i.EndsAt,
+		&i.MaxOccurrences,
+		&i.FinishedAt,
+		&i.OccurrenceCount,
+		&i.IntervalSeconds,
+		&i.AnchorAt,
+		&i.CalendarNames,
+		&i.MisfirePolicy,
+		&i.LastFiredAt,
+		&i.Tags,
+		&i.NextFireAt,
+		&i.MediaType,
+		&i.MediaFileID,
+	)
+	return i, err
+}
+
+const resumeJob = `-- name: ResumeJob :one
 UPDATE jobs
-SET river_job_id = $1
+SET paused_at = NULL
+WHERE id = $1
+AND paused_at IS NOT NULL
+AND deleted_at IS NULL
+RETURNING id, telegram_chat_id, is_recurring, river_job_id, message, schedule, name, created_at, updated_at, deleted_at, nag_interval_minutes, nag_max_count, paused_at, ends_at, max_occurrences, finished_at, occurrence_count, interval_seconds, anchor_at, calendar_names, misfire_policy, last_fired_at, tags, next_fire_at, media_type, media_file_id
+`
+
+func (q *Queries) ResumeJob(ctx context.Context, id int32) (Job, error) {
+	row := q.db.QueryRow(ctx, resumeJob, id)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramChatID,
+		&i.IsRecurring,
+		&i.RiverJobID,
+		&i.Message,
+		&i.Schedule,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.NagIntervalMinutes,
+		&i.NagMaxCount,
+		&i.PausedAt,
+		&i.EndsAt,
+		&i.MaxOccurrences,
+		&i.FinishedAt,
+		&i.OccurrenceCount,
+		&i.IntervalSeconds,
+		&i.AnchorAt,
+		&i.CalendarNames,
+		&i.MisfirePolicy,
+		&i.LastFiredAt,
+		&i.Tags,
+		&i.NextFireAt,
+		&i.MediaType,
+		&i.MediaFileID,
+	)
+	return i, err
+}
+
+const searchJobsByTelegramChatID = `-- name: SearchJobsByTelegramChatID :many
+SELECT id, is_recurring, message, schedule, name, paused_at, tags
+FROM jobs
+WHERE telegram_chat_id = $1
+AND deleted_at IS NULL
+AND (strpos(lower(name), lower($2::TEXT)) > 0
+    OR strpos(lower(message), lower($2::TEXT)) > 0
+    OR lower($2::TEXT) = ANY (tags))
+ORDER BY id
+LIMIT $3
+`
+
+type SearchJobsByTelegramChatIDParams struct {
+	TelegramChatID int64
+	Query          string
+	Limit          int32
+}
+
+type SearchJobsByTelegramChatIDRow struct {
+	ID          int32
+	IsRecurring bool
+	Message     string
+	Schedule    string
+	Name        string
+	PausedAt    pgtype.Timestamp
+	Tags        []string
+}
+
+func (q *Queries) SearchJobsByTelegramChatID(ctx context.Context, arg SearchJobsByTelegramChatIDParams) ([]SearchJobsByTelegramChatIDRow, error) {
+	rows, err := q.db.Query(ctx, searchJobsByTelegramChatID, arg.TelegramChatID, arg.Query, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchJobsByTelegramChatIDRow
+	for rows.Next() {
+		var i SearchJobsByTelegramChatIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.IsRecurring,
+			&i.Message,
+			&i.Schedule,
+			&i.Name,
+			&i.PausedAt,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateJobCalendars = `-- name: UpdateJobCalendars :one
+UPDATE jobs
+SET calendar_names = $1
+WHERE id = $2
+AND is_recurring = true
+AND deleted_at IS NULL
+RETURNING id, telegram_chat_id, is_recurring, river_job_id, message, schedule, name, created_at, updated_at, deleted_at, nag_interval_minutes, nag_max_count, paused_at, ends_at, max_occurrences, finished_at, occurrence_count, interval_seconds, anchor_at, calendar_names, misfire_policy, last_fired_at, tags, next_fire_at, media_type, media_file_id
+`
+
+type UpdateJobCalendarsParams struct {
+	CalendarNames []string
+	ID            int32
+}
+
+func (q *Queries) UpdateJobCalendars(ctx context.Context, arg UpdateJobCalendarsParams) (Job, error) {
+	row := q.db.QueryRow(ctx, updateJobCalendars, arg.CalendarNames, arg.ID)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramChatID,
+		&i.IsRecurring,
+		&i.RiverJobID,
+		&i.Message,
+		&i.Schedule,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.NagIntervalMinutes,
+		&i.NagMaxCount,
+		&i.PausedAt,
+		&i.EndsAt,
+		&i.MaxOccurrences,
+		&i.FinishedAt,
+		&i.OccurrenceCount,
+		&i.IntervalSeconds,
+		&i.AnchorAt,
+		&i.CalendarNames,
+		&i.MisfirePolicy,
+		&i.LastFiredAt,
+		&i.Tags,
+		&i.NextFireAt,
+		&i.MediaType,
+		&i.MediaFileID,
+	)
+	return i, err
+}
+
+const updateJobDetails = `-- name: UpdateJobDetails :one
+UPDATE jobs
+SET name = $1, message = $2, schedule = $3, interval_seconds = $4, anchor_at = $5
+WHERE id = $6
+AND deleted_at IS NULL
+RETURNING id, telegram_chat_id, is_recurring, river_job_id, message, schedule, name, created_at, updated_at, deleted_at, nag_interval_minutes, nag_max_count, paused_at, ends_at, max_occurrences, finished_at, occurrence_count, interval_seconds, anchor_at, calendar_names, misfire_policy, last_fired_at, tags, next_fire_at, media_type, media_file_id
+`
+
+type UpdateJobDetailsParams struct {
+	Name            string
+	Message         string
+	Schedule        string
+	IntervalSeconds pgtype.Int4
+	AnchorAt        pgtype.Timestamp
+	ID              int32
+}
+
+func (q *Queries) UpdateJobDetails(ctx context.Context, arg UpdateJobDetailsParams) (Job, error) {
+	row := q.db.QueryRow(ctx, updateJobDetails,
+		arg.Name,
+		arg.Message,
+		arg.Schedule,
+		arg.IntervalSeconds,
+		arg.AnchorAt,
+		arg.ID,
+	)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramChatID,
+		&i.IsRecurring,
+		&i.RiverJobID,
+		&i.Message,
+		&i.Schedule,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.NagIntervalMinutes,
+		&i.NagMaxCount,
+		&i.PausedAt,
+		&i.EndsAt,
+		&i.MaxOccurrences,
+		&i.FinishedAt,
+		&i.OccurrenceCount,
+		&i.IntervalSeconds,
+		&i.AnchorAt,
+		&i.CalendarNames,
+		&i.MisfirePolicy,
+		&i.LastFiredAt,
+		&i.Tags,
+		&i.NextFireAt,
+		&i.MediaType,
+		&i.MediaFileID,
+	)
+	return i, err
+}
+
+const updateJobLastFiredAt = `-- name: UpdateJobLastFiredAt :one
+UPDATE jobs
+SET last_fired_at = NOW()
+WHERE id = $1
+RETURNING id, telegram_chat_id, is_recurring, river_job_id, message, schedule, name, created_at, updated_at, deleted_at, nag_interval_minutes, nag_max_count, paused_at, ends_at, max_occurrences, finished_at, occurrence_count, interval_seconds, anchor_at, calendar_names, misfire_policy, last_fired_at, tags, next_fire_at, media_type, media_file_id
+`
+
+func (q *Queries) UpdateJobLastFiredAt(ctx context.Context, id int32) (Job, error) {
+	row := q.db.QueryRow(ctx, updateJobLastFiredAt, id)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramChatID,
+		&i.IsRecurring,
+		&i.RiverJobID,
+		&i.Message,
+		&i.Schedule,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.NagIntervalMinutes,
+		&i.NagMaxCount,
+		&i.PausedAt,
+		&i.EndsAt,
+		&i.MaxOccurrences,
+		&i.FinishedAt,
+		&i.OccurrenceCount,
+		&i.IntervalSeconds,
+		&i.AnchorAt,
+		&i.CalendarNames,
+		&i.MisfirePolicy,
+		&i.LastFiredAt,
+		&i.Tags,
+		&i.NextFireAt,
+		&i.MediaType,
+		&i.MediaFileID,
+	)
+	return i, err
+}
+
+const updateJobMisfirePolicy = `-- name: UpdateJobMisfirePolicy :one
+UPDATE jobs
+SET misfire_policy = $1
+WHERE id = $2
+AND deleted_at IS NULL
+RETURNING id, telegram_chat_id, is_recurring, river_job_id, message, schedule, name, created_at, updated_at, deleted_at, nag_interval_minutes, nag_max_count, paused_at, ends_at, max_occurrences, finished_at, occurrence_count, interval_seconds, anchor_at, calendar_names, misfire_policy, last_fired_at, tags, next_fire_at, media_type, media_file_id
+`
+
+type UpdateJobMisfirePolicyParams struct {
+	MisfirePolicy string
+	ID            int32
+}
+
+func (q *Queries) UpdateJobMisfirePolicy(ctx context.Context, arg UpdateJobMisfirePolicyParams) (Job, error) {
+	row := q.db.QueryRow(ctx, updateJobMisfirePolicy, arg.MisfirePolicy, arg.ID)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramChatID,
+		&i.IsRecurring,
+		&i.RiverJobID,
+		&i.Message,
+		&i.Schedule,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.NagIntervalMinutes,
+		&i.NagMaxCount,
+		&i.PausedAt,
+		&i.EndsAt,
+		&i.MaxOccurrences,
+		&i.FinishedAt,
+		&i.OccurrenceCount,
+		&i.IntervalSeconds,
+		&i.AnchorAt,
+		&i.CalendarNames,
+		&i.MisfirePolicy,
+		&i.LastFiredAt,
+		&i.Tags,
+		&i.NextFireAt,
+		&i.MediaType,
+		&i.MediaFileID,
+	)
+	return i, err
+}
+
+const updateJobNagPolicy = `-- name: UpdateJobNagPolicy :one
+UPDATE jobs
+SET nag_interval_minutes = $1, nag_max_count = $2
+WHERE id = $3
+RETURNING id, telegram_chat_id, is_recurring, river_job_id, message, schedule, name, created_at, updated_at, deleted_at, nag_interval_minutes, nag_max_count, paused_at, ends_at, max_occurrences, finished_at, occurrence_count, interval_seconds, anchor_at, calendar_names, misfire_policy, last_fired_at, tags, next_fire_at, media_type, media_file_id
+`
+
+type UpdateJobNagPolicyParams struct {
+	NagIntervalMinutes pgtype.Int4
+	NagMaxCount        pgtype.Int4
+	ID                 int32
+}
+
+func (q *Queries) UpdateJobNagPolicy(ctx context.Context, arg UpdateJobNagPolicyParams) (Job, error) {
+	row := q.db.QueryRow(ctx, updateJobNagPolicy, arg.NagIntervalMinutes, arg.NagMaxCount, arg.ID)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramChatID,
+		&i.IsRecurring,
+		&i.RiverJobID,
+		&i.Message,
+		&i.Schedule,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.NagIntervalMinutes,
+		&i.NagMaxCount,
+		&i.PausedAt,
+		&i.EndsAt,
+		&i.MaxOccurrences,
+		&i.FinishedAt,
+		&i.OccurrenceCount,
+		&i.IntervalSeconds,
+		&i.AnchorAt,
+		&i.CalendarNames,
+		&i.MisfirePolicy,
+		&i.LastFiredAt,
+		&i.Tags,
+		&i.NextFireAt,
+		&i.MediaType,
+		&i.MediaFileID,
+	)
+	return i, err
+}
+
+const updateJobTags = `-- name: UpdateJobTags :one
+UPDATE jobs
+SET tags = $1
 WHERE id = $2
-RETURNING id, telegram_chat_id, is_recurring, river_job_id, message, schedule, name, created_at, updated_at, deleted_at
+AND deleted_at IS NULL
+RETURNING id, telegram_chat_id, is_recurring, river_job_id, message, schedule, name, created_at, updated_at, deleted_at, nag_interval_minutes, nag_max_count, paused_at, ends_at, max_occurrences, finished_at, occurrence_count, interval_seconds, anchor_at, calendar_names, misfire_policy, last_fired_at, tags, next_fire_at, media_type, media_file_id
+`
+
+type UpdateJobTagsParams struct {
+	Tags []string
+	ID   int32
+}
+
+func (q *Queries) UpdateJobTags(ctx context.Context, arg UpdateJobTagsParams) (Job, error) {
+	row := q.db.QueryRow(ctx, updateJobTags, arg.Tags, arg.ID)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.TelegramChatID,
+		&i.IsRecurring,
+		&i.RiverJobID,
+		&i.Message,
+		&i.Schedule,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.NagIntervalMinutes,
+		&i.NagMaxCount,
+		&i.PausedAt,
+		&i.EndsAt,
+		&i.MaxOccurrences,
+		&i.FinishedAt,
+		&i.OccurrenceCount,
+		&i.IntervalSeconds,
+		&i.AnchorAt,
+		&i.CalendarNames,
+		&i.MisfirePolicy,
+		&i.LastFiredAt,
+		&i.Tags,
+		&i.NextFireAt,
+		&i.MediaType,
+		&i.MediaFileID,
+	)
+	return i, err
+}
+
+const updateRiverJobID = `-- name: UpdateRiverJobID :one
+UPDATE jobs
+SET river_job_id = $1, next_fire_at = $2
+WHERE id = $3
+RETURNING id, telegram_chat_id, is_recurring, river_job_id, message, schedule, name, created_at, updated_at, deleted_at, nag_interval_minutes, nag_max_count, paused_at, ends_at, max_occurrences, finished_at, occurrence_count, interval_seconds, anchor_at, calendar_names, misfire_policy, last_fired_at, tags, next_fire_at, media_type, media_file_id
 `
 
 type UpdateRiverJobIDParams struct {
 	RiverJobID pgtype.Int8
+	NextFireAt pgtype.Timestamp
 	ID         int32
 }
 
 func (q *Queries) UpdateRiverJobID(ctx context.Context, arg UpdateRiverJobIDParams) (Job, error) {
-	row := q.db.QueryRow(ctx, updateRiverJobID, arg.RiverJobID, arg.ID)
+	row := q.db.QueryRow(ctx, updateRiverJobID, arg.RiverJobID, arg.NextFireAt, arg.ID)
 	var i Job
 	err := row.Scan(
 		&i.ID,
@@ -250,6 +1110,22 @@ func (q *Queries) UpdateRiverJobID(ctx context.Context, arg UpdateRiverJobIDPara
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.NagIntervalMinutes,
+		&i.NagMaxCount,
+		&i.PausedAt,
+		&i.EndsAt,
+		&i.MaxOccurrences,
+		&i.FinishedAt,
+		&i.OccurrenceCount,
+		&i.IntervalSeconds,
+		&i.AnchorAt,
+		&i.CalendarNames,
+		&i.MisfirePolicy,
+		&i.LastFiredAt,
+		&i.Tags,
+		&i.NextFireAt,
+		&i.MediaType,
+		&i.MediaFileID,
 	)
 	return i, err
 }
@@ -8,24 +8,106 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type Chat struct {
+type Calendar struct {
 	ID             int32
 	TelegramChatID int64
-	Context        []byte
+	Name           string
 	CreatedAt      pgtype.Timestamp
 	UpdatedAt      pgtype.Timestamp
 	DeletedAt      pgtype.Timestamp
 }
 
+type CalendarDate struct {
+	ID         int32
+	CalendarID int32
+	Date       pgtype.Date
+	CreatedAt  pgtype.Timestamp
+	UpdatedAt  pgtype.Timestamp
+	DeletedAt  pgtype.Timestamp
+}
+
+type Chat struct {
+	ID               int32
+	TelegramChatID   int64
+	Context          []byte
+	CreatedAt        pgtype.Timestamp
+	UpdatedAt        pgtype.Timestamp
+	DeletedAt        pgtype.Timestamp
+	TimeZone         string
+	QuietHoursStart  pgtype.Int4
+	QuietHoursEnd    pgtype.Int4
+	QuietHoursPolicy string
+}
+
+type Delivery struct {
+	ID                int32
+	JobID             pgtype.Int4
+	RiverJobID        int64
+	TelegramChatID    int64
+	TelegramMessageID pgtype.Int8
+	NagCount          int32
+	SentAt            pgtype.Timestamp
+	AcknowledgedAt    pgtype.Timestamp
+	CreatedAt         pgtype.Timestamp
+	UpdatedAt         pgtype.Timestamp
+	DeletedAt         pgtype.Timestamp
+	FireAt            pgtype.Timestamp
+	Status            string
+	Error             pgtype.Text
+	SnoozedDeliveryID pgtype.Int4
+	DeferredUntil     pgtype.Timestamp
+}
+
 type Job struct {
+	ID                 int32
+	TelegramChatID     int64
+	IsRecurring        bool
+	RiverJobID         pgtype.Int8
+	Message            string
+	Schedule           string
+	Name               string
+	CreatedAt          pgtype.Timestamp
+	UpdatedAt          pgtype.Timestamp
+	DeletedAt          pgtype.Timestamp
+	NagIntervalMinutes pgtype.Int4
+	NagMaxCount        pgtype.Int4
+	PausedAt           pgtype.Timestamp
+	EndsAt             pgtype.Timestamp
+	MaxOccurrences     pgtype.Int4
+	FinishedAt         pgtype.Timestamp
+	OccurrenceCount    int64
+	IntervalSeconds    pgtype.Int4
+	AnchorAt           pgtype.Timestamp
+	CalendarNames      []string
+	MisfirePolicy      string
+	LastFiredAt        pgtype.Timestamp
+	Tags               []string
+	NextFireAt         pgtype.Timestamp
+	MediaType          string
+	MediaFileID        string
+}
+
+type JobSkip struct {
+	ID        int32
+	JobID     int32
+	FireAt    pgtype.Timestamp
+	SkipDate  pgtype.Date
+	CreatedAt pgtype.Timestamp
+	UpdatedAt pgtype.Timestamp
+	DeletedAt pgtype.Timestamp
+}
+
+type Template struct {
 	ID             int32
 	TelegramChatID int64
-	IsRecurring    bool
-	RiverJobID     pgtype.Int8
-	Message        string
-	Schedule       string
 	Name           string
+	JobName        string
+	Message        string
+	IsRecurring    pgtype.Bool
+	IsInterval     bool
 	CreatedAt      pgtype.Timestamp
 	UpdatedAt      pgtype.Timestamp
 	DeletedAt      pgtype.Timestamp
+	MediaType      string
+	MediaFileID    string
 }
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/cohesion-org/deepseek-go"
@@ -60,7 +61,7 @@ func main() {
 	deepSeekClient := deepseekai.NewClient(envCfg.DeepSeekAPIKey)
 
 	commandsHandler := commands.NewHandler(botClient, queries, riverClient, cache)
-	messagesHandler := messages.NewHandler(botClient, queries, deepSeekClient, cache)
+	messagesHandler := messages.NewHandler(botClient, queries, riverClient, deepSeekClient, cache)
 	callbackQueriesHandler := callbackqueries.NewHandler(botClient, queries, riverClient, pool)
 
 	server := &http.Server{
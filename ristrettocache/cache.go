@@ -29,7 +29,7 @@ func NewCache[T any]() (*Cache[T], error) {
 func (c *Cache[T]) Set(key string, val T) error {
 	bytes, err := json.Marshal(val)
 	if err != nil {
-		return fmt.Errorf("failed to set cache [key: %s][value: %s]: %w", key, val, err)
+		return fmt.Errorf("failed to set cache [key: %s][value: %v]: %w", key, val, err)
 	}
 	cost := len(string(bytes))
 	c.Cache.Set(key, string(bytes), int64(cost))
@@ -0,0 +1,203 @@
+package riverjobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+	"github.com/rs/zerolog/log"
+
+	"remembertelebot/bot"
+	"remembertelebot/db/sqlc"
+)
+
+const (
+	DeliveryStatusPending = "pending"
+	DeliveryStatusSent    = "sent"
+	DeliveryStatusFailed  = "failed"
+	DeliveryStatusDropped = "dropped"
+)
+
+// deliverReminder sends the reminder of a fired river job once. recurringJobID is the ID of the recurring job it
+// belongs to, or 0 if there is none, and adds a button to skip the job's next occurrence.
+func deliverReminder(ctx context.Context, botClient *bot.Client, queries *sqlc.Queries, riverJob *rivertype.JobRow,
+	jobID int32, chatID int64, message string, media bot.Media, recurringJobID int32) error {
+	delivery, err := queries.CreateDelivery(ctx, sqlc.CreateDeliveryParams{
+		JobID:          pgtype.Int4{Valid: jobID != 0, Int32: jobID},
+		RiverJobID:     riverJob.ID,
+		TelegramChatID: chatID,
+		FireAt:         pgtype.Timestamp{Valid: true, Time: riverJob.ScheduledAt.UTC()},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create delivery [riverJobID: %v]: %w", riverJob.ID, err)
+	}
+
+	// a retried river job must not send the same occurrence twice
+	if !delivery.SentAt.Valid {
+		if err := sendReminder(ctx, botClient, queries, riverJob, delivery.ID, chatID, message, media,
+			recurringJobID); err != nil {
+			return err
+		}
+
+		if jobID != 0 {
+			if _, err := queries.UpdateJobLastFiredAt(ctx, jobID); err != nil {
+				log.Err(err).Msgf("Unable to update job last fired at [jobID: %v].", jobID)
+			}
+		}
+	}
+
+	return scheduleNag(ctx, queries, delivery.ID)
+}
+
+func sendReminder(ctx context.Context, botClient *bot.Client, queries *sqlc.Queries, riverJob *rivertype.JobRow,
+	deliveryID int32, chatID int64, message string, media bot.Media, recurringJobID int32) error {
+	quietHours, isQuiet, windowEnd, err := getQuietHours(ctx, queries, chatID)
+	if err != nil {
+		return err
+	}
+
+	send := botClient.SendMediaMarkupMessage
+	if isQuiet {
+		switch quietHours.Policy {
+		case QuietHoursPolicyDrop:
+			log.Info().Msgf("Dropping reminder during quiet hours [deliveryID: %v][telegramChatID: %v].", deliveryID,
+				chatID)
+			if _, err := queries.UpdateDeliveryDropped(ctx, deliveryID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("failed to update delivery dropped [deliveryID: %v]: %w", deliveryID, err)
+			}
+			return nil
+		case QuietHoursPolicySilent:
+			send = botClient.SendSilentMediaMarkupMessage
+		default:
+			return deferReminder(ctx, queries, deliveryID, windowEnd, recurringJobID)
+		}
+	}
+
+	messageID, err := send(ctx, chatID, media, message, NewReminderKeyboard(deliveryID, recurringJobID))
+	if err != nil {
+		if _, updateErr := queries.UpdateDeliveryFailed(ctx, sqlc.UpdateDeliveryFailedParams{
+			Error: pgtype.Text{Valid: true, String: err.Error()},
+			ID:    deliveryID,
+		}); updateErr != nil {
+			log.Warn().Err(updateErr).Msgf("Unable to update delivery failed [deliveryID: %v].", deliveryID)
+		}
+		return handleSendError(ctx, botClient, riverJob, chatID, err)
+	}
+
+	if _, err := queries.UpdateDeliverySent(ctx, sqlc.UpdateDeliverySentParams{
+		TelegramMessageID: pgtype.Int8{Valid: true, Int64: int64(messageID)},
+		ID:                deliveryID,
+	}); err != nil {
+		return fmt.Errorf("failed to update delivery sent [deliveryID: %v]: %w", deliveryID, err)
+	}
+	return nil
+}
+
+// deferReminder holds a reminder until quiet hours end. The occurrences of a recurring job that fall in the same quiet
+// hours are only sent once, by the first of them, so that a frequent job does not pile up reminders for the morning.
+func deferReminder(ctx context.Context, queries *sqlc.Queries, deliveryID int32, windowEnd time.Time,
+	recurringJobID int32) error {
+	if recurringJobID != 0 {
+		_, err := queries.DeferDelivery(ctx, sqlc.DeferDeliveryParams{
+			DeferredUntil: pgtype.Timestamp{Valid: true, Time: windowEnd.UTC()},
+			ID:            deliveryID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Info().Msgf("Dropping reminder already deferred until quiet hours end [deliveryID: %v][windowEnd: %v].",
+				deliveryID, windowEnd)
+			if _, err := queries.UpdateDeliveryDropped(ctx, deliveryID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("failed to update delivery dropped [deliveryID: %v]: %w", deliveryID, err)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to defer delivery [deliveryID: %v]: %w", deliveryID, err)
+		}
+	}
+
+	log.Info().Msgf("Deferring reminder until quiet hours end [deliveryID: %v][windowEnd: %v].", deliveryID, windowEnd)
+	return river.JobSnooze(time.Until(windowEnd))
+}
+
+// getQuietHours returns the quiet hours of a chat, whether they are in effect now and, if so, when they end.
+func getQuietHours(ctx context.Context, queries *sqlc.Queries, chatID int64) (QuietHours, bool, time.Time, error) {
+	chat, err := queries.GetChat(ctx, chatID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return QuietHours{}, false, time.Time{}, nil
+	}
+	if err != nil {
+		return QuietHours{}, false, time.Time{}, fmt.Errorf("failed to get chat [telegramChatID: %v]: %w", chatID, err)
+	}
+
+	quietHours, ok := NewQuietHours(chat.QuietHoursStart, chat.QuietHoursEnd, chat.QuietHoursPolicy)
+	if !ok {
+		return QuietHours{}, false, time.Time{}, nil
+	}
+	windowEnd, isQuiet := quietHours.WindowEnd(time.Now(), LoadLocation(chat.TimeZone))
+	return quietHours, isQuiet, windowEnd, nil
+}
+
+// handleSendError decides how river should treat a failed send: flood control snoozes the job for as long as
+// Telegram asks, permanent rejections cancel it, and anything else is retried with river's usual backoff.
+func handleSendError(ctx context.Context, botClient *bot.Client, riverJob *rivertype.JobRow, chatID int64,
+	err error) error {
+	if retryAfter, ok := bot.RetryAfter(err); ok {
+		log.Warn().Err(err).Msgf("Rate limited by telegram, snoozing job [riverJobID: %v][retryAfter: %s].",
+			riverJob.ID, retryAfter.String())
+		return river.JobSnooze(retryAfter)
+	}
+
+	if bot.IsPermanentError(err) {
+		notifyUndelivered(ctx, botClient, chatID, err)
+		return river.JobCancel(err)
+	}
+
+	if riverJob.Attempt >= riverJob.MaxAttempts {
+		notifyUndelivered(ctx, botClient, chatID, err)
+	}
+	return err
+}
+
+func notifyUndelivered(ctx context.Context, botClient *bot.Client, chatID int64, err error) {
+	// there is no one to tell when the bot can no longer message the chat
+	if bot.IsForbiddenError(err) {
+		return
+	}
+
+	if sendErr := botClient.SendPlainMessage(ctx, chatID, fmt.Sprintf("⚠️ A reminder could not be delivered: %s",
+		err.Error())); sendErr != nil {
+		log.Err(sendErr).Msgf("Unable to notify chat of undelivered reminder [telegramChatID: %v].", chatID)
+	}
+}
+
+func scheduleNag(ctx context.Context, queries *sqlc.Queries, deliveryID int32) error {
+	policy, err := queries.GetDeliveryNagPolicy(ctx, deliveryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// deliveries that do not belong to a job are never nagged
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get delivery nag policy [deliveryID: %v]: %w", deliveryID, err)
+	}
+
+	if policy.AcknowledgedAt.Valid || !policy.NagIntervalMinutes.Valid || !policy.NagMaxCount.Valid ||
+		policy.NagCount >= policy.NagMaxCount.Int32 {
+		return nil
+	}
+
+	if _, err := river.ClientFromContext[pgx.Tx](ctx).Insert(ctx, NagJobArgs{
+		DeliveryID: deliveryID,
+		NagCount:   policy.NagCount + 1,
+	}, &river.InsertOpts{
+		ScheduledAt: time.Now().Add(time.Duration(policy.NagIntervalMinutes.Int32) * time.Minute),
+	}); err != nil {
+		return fmt.Errorf("failed to add nag job [deliveryID: %v]: %w", deliveryID, err)
+	}
+	return nil
+}
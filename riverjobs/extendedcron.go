@@ -0,0 +1,199 @@
+package riverjobs
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// maxSearchDays bounds the search for the next matching day, like the 5 years cron.SpecSchedule searches.
+const maxSearchDays = 5 * 366
+
+var (
+	lastDayRegex        = regexp.MustCompile(`^L(?:-([0-9]{1,2}))?$`)
+	nearestWeekdayRegex = regexp.MustCompile(`^([0-9]{1,2})W$`)
+	nthWeekdayRegex     = regexp.MustCompile(`^([0-7]|SUN|MON|TUE|WED|THU|FRI|SAT)#([1-5])$`)
+	lastWeekdayRegex    = regexp.MustCompile(`^([0-7]|SUN|MON|TUE|WED|THU|FRI|SAT)L$`)
+
+	weekdayNames = map[string]time.Weekday{
+		"SUN": time.Sunday, "MON": time.Monday, "TUE": time.Tuesday, "WED": time.Wednesday,
+		"THU": time.Thursday, "FRI": time.Friday, "SAT": time.Saturday,
+	}
+)
+
+// ExtendedSchedule is a cron schedule whose day of month or day of week uses the Quartz-style L, W or # syntax:
+//   - L is the last day of the month and L-n is n days before it, e.g. L-2.
+//   - LW is the last weekday (Monday to Friday) of the month.
+//   - nW is the weekday nearest to day n, staying within the month, e.g. 15W.
+//   - dL is the last day of week d of the month, e.g. 5L or FRIL for the last Friday.
+//   - d#k is the k-th day of week d of the month, e.g. 2#2 or TUE#2 for the second Tuesday.
+//
+// The other day field must then be * or ?.
+type ExtendedSchedule struct {
+	times    *cron.SpecSchedule
+	matchDay func(date time.Time) bool
+}
+
+// IsExtendedCronTab reports whether a 5-field cron tab uses L, W or # in its day of month or day of week.
+func IsExtendedCronTab(cronTab string) bool {
+	fields := strings.Fields(strings.ToUpper(cronTab))
+	if len(fields) != 5 {
+		return false
+	}
+	return strings.ContainsAny(fields[2], "LW") || strings.Contains(fields[4], "#") ||
+		strings.HasSuffix(fields[4], "L")
+}
+
+func ParseExtendedCronTab(cronTab string, loc *time.Location) (*ExtendedSchedule, error) {
+	fields := strings.Fields(strings.ToUpper(cronTab))
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected exactly 5 fields, found %d", len(fields))
+	}
+
+	// Minutes, hours and months are standard, so let cron match them on any day.
+	schedule, err := cron.ParseStandard(strings.Join([]string{fields[0], fields[1], "*", fields[3], "*"}, " "))
+	if err != nil {
+		return nil, err
+	}
+	times, ok := schedule.(*cron.SpecSchedule)
+	if !ok {
+		return nil, errors.New("expected a standard cron tab")
+	}
+	times.Location = loc
+
+	dayOfMonth, dayOfWeek := fields[2], fields[4]
+	isAnyDayOfMonth := dayOfMonth == "*" || dayOfMonth == "?"
+	isAnyDayOfWeek := dayOfWeek == "*" || dayOfWeek == "?"
+
+	var matchDay func(date time.Time) bool
+	switch {
+	case isAnyDayOfWeek && !isAnyDayOfMonth:
+		matchDay, err = parseExtendedDayOfMonth(dayOfMonth)
+	case isAnyDayOfMonth && !isAnyDayOfWeek:
+		matchDay, err = parseExtendedDayOfWeek(dayOfWeek)
+	default:
+		err = errors.New("when using L, W or #, the other day field must be * or ?")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ExtendedSchedule{times: times, matchDay: matchDay}, nil
+}
+
+func parseExtendedDayOfMonth(field string) (func(date time.Time) bool, error) {
+	if field == "LW" {
+		return func(date time.Time) bool {
+			last := time.Date(date.Year(), date.Month(), daysIn(date), 0, 0, 0, 0, date.Location())
+			for last.Weekday() == time.Saturday || last.Weekday() == time.Sunday {
+				last = last.AddDate(0, 0, -1)
+			}
+			return date.Day() == last.Day()
+		}, nil
+	}
+
+	if matches := lastDayRegex.FindStringSubmatch(field); matches != nil {
+		offset := 0
+		if matches[1] != "" {
+			offset, _ = strconv.Atoi(matches[1])
+			if offset < 1 || offset > 30 {
+				return nil, fmt.Errorf("days before the last day must be between 1 and 30 [field: %s]", field)
+			}
+		}
+		return func(date time.Time) bool {
+			return date.Day() == daysIn(date)-offset
+		}, nil
+	}
+
+	if matches := nearestWeekdayRegex.FindStringSubmatch(field); matches != nil {
+		target, _ := strconv.Atoi(matches[1])
+		if target < 1 || target > 31 {
+			return nil, fmt.Errorf("day of month must be between 1 and 31 [field: %s]", field)
+		}
+		return func(date time.Time) bool {
+			return date.Day() == nearestWeekday(date, target)
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported day of month, expected L, L-n, LW or nW [field: %s]", field)
+}
+
+func parseExtendedDayOfWeek(field string) (func(date time.Time) bool, error) {
+	if matches := nthWeekdayRegex.FindStringSubmatch(field); matches != nil {
+		weekday := parseWeekday(matches[1])
+		nth, _ := strconv.Atoi(matches[2])
+		return func(date time.Time) bool {
+			return date.Weekday() == weekday && (date.Day()-1)/7+1 == nth
+		}, nil
+	}
+
+	if matches := lastWeekdayRegex.FindStringSubmatch(field); matches != nil {
+		weekday := parseWeekday(matches[1])
+		return func(date time.Time) bool {
+			return date.Weekday() == weekday && date.Day()+7 > daysIn(date)
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported day of week, expected dL or d#k [field: %s]", field)
+}
+
+// parseWeekday parses a day of week already matched by a regex, i.e. 0-7 (both 0 and 7 are Sunday) or SUN-SAT.
+func parseWeekday(text string) time.Weekday {
+	if weekday, ok := weekdayNames[text]; ok {
+		return weekday
+	}
+	n, _ := strconv.Atoi(text)
+	return time.Weekday(n % 7)
+}
+
+func daysIn(date time.Time) int {
+	return time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, date.Location()).Day()
+}
+
+// nearestWeekday returns the weekday nearest to the target day in date's month without leaving the month, or 0 if
+// the month is too short to have the target day.
+func nearestWeekday(date time.Time, target int) int {
+	days := daysIn(date)
+	if target > days {
+		return 0
+	}
+
+	switch time.Date(date.Year(), date.Month(), target, 0, 0, 0, 0, date.Location()).Weekday() {
+	case time.Saturday:
+		if target == 1 {
+			return target + 2
+		}
+		return target - 1
+	case time.Sunday:
+		if target == days {
+			return target - 2
+		}
+		return target + 1
+	default:
+		return target
+	}
+}
+
+func (s *ExtendedSchedule) Next(t time.Time) time.Time {
+	loc := s.times.Location
+	t = t.In(loc)
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	for i := 0; i < maxSearchDays; i++ {
+		if s.matchDay(date) {
+			from := date.Add(-time.Second)
+			if i == 0 {
+				from = t
+			}
+			next := s.times.Next(from)
+			if next.Year() == date.Year() && next.YearDay() == date.YearDay() {
+				return next
+			}
+		}
+		date = time.Date(date.Year(), date.Month(), date.Day()+1, 0, 0, 0, 0, loc)
+	}
+	return time.Time{}
+}
@@ -0,0 +1,176 @@
+package riverjobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExtendedScheduleNext(t *testing.T) {
+	tests := []struct {
+		name    string
+		cronTab string
+		after   time.Time
+		want    time.Time
+	}{
+		{
+			name:    "last day of month",
+			cronTab: "0 9 L * *",
+			after:   time.Date(2025, time.January, 15, 10, 0, 0, 0, time.UTC),
+			want:    time.Date(2025, time.January, 31, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "last day of month later the same day",
+			cronTab: "0 9 L * *",
+			after:   time.Date(2025, time.January, 31, 8, 0, 0, 0, time.UTC),
+			want:    time.Date(2025, time.January, 31, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "last day of month already passed today",
+			cronTab: "0 9 L * *",
+			after:   time.Date(2025, time.January, 31, 10, 0, 0, 0, time.UTC),
+			want:    time.Date(2025, time.February, 28, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "days before the last day",
+			cronTab: "0 9 L-2 * *",
+			after:   time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+			want:    time.Date(2025, time.February, 26, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "last weekday of month on a saturday",
+			cronTab: "0 9 LW * *",
+			after:   time.Date(2025, time.May, 1, 0, 0, 0, 0, time.UTC),
+			want:    time.Date(2025, time.May, 30, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "nearest weekday before a saturday",
+			cronTab: "0 9 15W * *",
+			after:   time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+			want:    time.Date(2025, time.March, 14, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "nearest weekday stays within the month",
+			cronTab: "0 9 1W * *",
+			after:   time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+			want:    time.Date(2025, time.February, 3, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "nearest weekday skips short months",
+			cronTab: "0 9 31W * *",
+			after:   time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+			want:    time.Date(2025, time.July, 31, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "nth day of week",
+			cronTab: "0 9 * * 2#2",
+			after:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			want:    time.Date(2025, time.January, 14, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "fifth day of week skips months without one",
+			cronTab: "30 8 * * 5#5",
+			after:   time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+			want:    time.Date(2025, time.May, 30, 8, 30, 0, 0, time.UTC),
+		},
+		{
+			name:    "last day of week by name",
+			cronTab: "0 9 * * FRIL",
+			after:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			want:    time.Date(2025, time.January, 31, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "sunday as 7",
+			cronTab: "0 9 * * 7#1",
+			after:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			want:    time.Date(2025, time.January, 5, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "nearest weekday of a day february never has",
+			cronTab: "0 9 31W 2 *",
+			after:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			want:    time.Time{},
+		},
+		{
+			name:    "fifth friday of february gives up after the search window",
+			cronTab: "0 9 * 2 5#5",
+			after:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			want:    time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseExtendedCronTab(tt.cronTab, time.UTC)
+			if err != nil {
+				t.Fatalf("ParseExtendedCronTab(%q) returned error: %v", tt.cronTab, err)
+			}
+			if got := schedule.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtendedScheduleNextInLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	schedule, err := ParseExtendedCronTab("0 1 L * *", loc)
+	if err != nil {
+		t.Fatalf("ParseExtendedCronTab returned error: %v", err)
+	}
+
+	// 22:30 UTC on the 30th is already the last day of the month in UTC+2
+	got := schedule.Next(time.Date(2025, time.January, 30, 22, 30, 0, 0, time.UTC))
+	want := time.Date(2025, time.January, 31, 1, 0, 0, 0, loc)
+	if !got.Equal(want) {
+		t.Errorf("Next = %v, want %v", got, want)
+	}
+}
+
+func TestParseExtendedCronTabErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		cronTab string
+	}{
+		{name: "too few fields", cronTab: "0 9 L *"},
+		{name: "both day fields set", cronTab: "0 9 L * MON"},
+		{name: "neither day field extended", cronTab: "0 9 * * *"},
+		{name: "too many days before the last day", cronTab: "0 9 L-31 * *"},
+		{name: "nearest weekday out of range", cronTab: "0 9 32W * *"},
+		{name: "nearest weekday of day zero", cronTab: "0 9 0W * *"},
+		{name: "unsupported day of month", cronTab: "0 9 LX * *"},
+		{name: "nth day of week out of range", cronTab: "0 9 * * MON#6"},
+		{name: "unsupported day of week", cronTab: "0 9 * * 8#1"},
+		{name: "invalid minutes", cronTab: "60 9 L * *"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseExtendedCronTab(tt.cronTab, time.UTC); err == nil {
+				t.Errorf("ParseExtendedCronTab(%q) returned no error", tt.cronTab)
+			}
+		})
+	}
+}
+
+func TestIsExtendedCronTab(t *testing.T) {
+	tests := []struct {
+		cronTab string
+		want    bool
+	}{
+		{cronTab: "0 9 * * *", want: false},
+		{cronTab: "0 9 1 * MON", want: false},
+		{cronTab: "0 9 l * *", want: true},
+		{cronTab: "0 9 15W * *", want: true},
+		{cronTab: "0 9 * * 2#2", want: true},
+		{cronTab: "0 9 * * friL", want: true},
+		{cronTab: "0 9 * *", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cronTab, func(t *testing.T) {
+			if got := IsExtendedCronTab(tt.cronTab); got != tt.want {
+				t.Errorf("IsExtendedCronTab(%q) = %v, want %v", tt.cronTab, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package riverjobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/riverqueue/river/rivertype"
+
+	"remembertelebot/bot"
+	"remembertelebot/db/sqlc"
+)
+
+// What happens to the occurrences of a job that were due while the bot was unavailable.
+const (
+	MisfirePolicySkip = "skip"
+	MisfirePolicyOnce = "once"
+	MisfirePolicyAll  = "all"
+)
+
+const (
+	// misfireThreshold is how long before the process started an occurrence must have been due to count as missed,
+	// so that one due just before a quick restart is only delayed.
+	misfireThreshold      = 5 * time.Minute
+	maxCatchUpOccurrences = 10
+)
+
+// processStartedAt is when the bot started. Occurrences due while it is running are never missed, however late a
+// backlog makes them start.
+var processStartedAt = time.Now()
+
+// misfire describes the occurrences of a recurring job that were missed, of which Resent are sent late and Capped are
+// not, because only maxCatchUpOccurrences are caught up.
+type misfire struct {
+	FirstFireAt time.Time
+	Count       int
+	Resent      int
+	Capped      int
+}
+
+func DescribeMisfirePolicy(policy string) string {
+	switch policy {
+	case MisfirePolicySkip:
+		return "skip reminders missed while the bot was unavailable"
+	case MisfirePolicyAll:
+		return "send every reminder missed while the bot was unavailable, with a summary"
+	default:
+		return "send a reminder missed while the bot was unavailable once"
+	}
+}
+
+// isMisfire reports whether a river job starting its first attempt was due while the bot was unavailable, i.e. well
+// before the bot started. Later attempts are retries, snoozes or deferrals, which are late on purpose.
+func isMisfire(riverJob *rivertype.JobRow) bool {
+	return riverJob.Attempt == 1 && processStartedAt.Sub(riverJob.ScheduledAt) > misfireThreshold
+}
+
+// missedFireAts returns the last (up to) limit fire times of a recurring job after the given time and before now or its
+// end, along with how many fire times there are in total.
+func missedFireAts(periodicJob *sqlc.GetActiveRecurringJobForUpdateRow, after time.Time, limit int) ([]time.Time, int,
+	error) {
+	schedule, err := NewRecurrence(periodicJob.Schedule, periodicJob.IntervalSeconds,
+		periodicJob.AnchorAt).Schedule(LoadLocation(periodicJob.TimeZone))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	end := time.Now()
+	if periodicJob.EndsAt.Valid && periodicJob.EndsAt.Time.Before(end) {
+		end = periodicJob.EndsAt.Time
+	}
+
+	var fireAts []time.Time
+	count := 0
+	for fireAt := schedule.Next(after); !fireAt.IsZero() && fireAt.Before(end); fireAt = schedule.Next(fireAt) {
+		count++
+		fireAts = append(fireAts, fireAt)
+		if len(fireAts) > limit {
+			fireAts = fireAts[1:]
+		}
+	}
+	return fireAts, count, nil
+}
+
+func notifyMisfire(ctx context.Context, botClient *bot.Client, periodicJob *sqlc.GetActiveRecurringJobForUpdateRow,
+	m *misfire) error {
+	return botClient.SendPlainMessage(ctx, periodicJob.TelegramChatID, describeMisfire(periodicJob.Name, m,
+		LoadLocation(periodicJob.TimeZone)))
+}
+
+func describeMisfire(name string, m *misfire, loc *time.Location) string {
+	text := fmt.Sprintf("%d reminder(s) of job %s were missed while I was unavailable, starting %s. ", m.Count, name,
+		FormatLocalTime(m.FirstFireAt, loc))
+	switch {
+	case m.Resent == 0:
+		text += "All of them were skipped or excluded."
+	case m.Resent < m.Count:
+		text += fmt.Sprintf("Sending %d of them now.", m.Resent)
+	default:
+		text += "Sending them now."
+	}
+	if m.Capped > 0 {
+		text += fmt.Sprintf(" At most %d are sent late, so %d of them will not be sent.", maxCatchUpOccurrences,
+			m.Capped)
+	}
+	return text
+}
+
+// notifySkippedMisfire tells a chat that a once-off reminder was skipped because it was due while the bot was
+// unavailable.
+func notifySkippedMisfire(ctx context.Context, botClient *bot.Client, queries *sqlc.Queries, chatID int64,
+	name string, fireAt time.Time) error {
+	chat, err := queries.GetChat(ctx, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to get chat [telegramChatID: %v]: %w", chatID, err)
+	}
+	return botClient.SendPlainMessage(ctx, chatID, fmt.Sprintf("Reminder %s was due at %s while I was unavailable and "+
+		"has been skipped.", name, FormatLocalTime(fireAt, LoadLocation(chat.TimeZone))))
+}
@@ -0,0 +1,91 @@
+package riverjobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"remembertelebot/db/sqlc"
+)
+
+func TestMissedFireAts(t *testing.T) {
+	after := time.Now().Truncate(time.Hour).Add(-48 * time.Hour)
+	periodicJob := &sqlc.GetActiveRecurringJobForUpdateRow{
+		Schedule: "0 * * * *",
+		TimeZone: "UTC",
+		EndsAt:   pgtype.Timestamp{Valid: true, Time: after.Add(24*time.Hour + 30*time.Minute)},
+	}
+
+	tests := []struct {
+		name      string
+		limit     int
+		wantCount int
+		wantFirst time.Time
+		wantLen   int
+	}{
+		{name: "within the limit", limit: 30, wantCount: 24, wantFirst: after.Add(time.Hour), wantLen: 24},
+		{
+			// only the latest occurrences before the end are kept
+			name:      "over the limit",
+			limit:     maxCatchUpOccurrences - 1,
+			wantCount: 24,
+			wantFirst: after.Add(time.Duration(24-maxCatchUpOccurrences+2) * time.Hour),
+			wantLen:   maxCatchUpOccurrences - 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fireAts, count, err := missedFireAts(periodicJob, after, tt.limit)
+			if err != nil {
+				t.Fatalf("missedFireAts returned error: %v", err)
+			}
+			if count != tt.wantCount || len(fireAts) != tt.wantLen {
+				t.Fatalf("missedFireAts = %d fire times of %d, want %d of %d", len(fireAts), count, tt.wantLen,
+					tt.wantCount)
+			}
+			if !fireAts[0].Equal(tt.wantFirst) || !fireAts[len(fireAts)-1].Equal(after.Add(24*time.Hour)) {
+				t.Errorf("missedFireAts = %v to %v, want %v to %v", fireAts[0], fireAts[len(fireAts)-1], tt.wantFirst,
+					after.Add(24*time.Hour))
+			}
+		})
+	}
+}
+
+func TestDescribeMisfire(t *testing.T) {
+	firstFireAt := time.Date(2025, time.January, 15, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		missed misfire
+		want   string
+	}{
+		{
+			name:   "all sent",
+			missed: misfire{FirstFireAt: firstFireAt, Count: 3, Resent: 3},
+			want: "3 reminder(s) of job standup were missed while I was unavailable, starting 2025-01-15 09:00:00 " +
+				"(UTC). Sending them now.",
+		},
+		{
+			name:   "all skipped",
+			missed: misfire{FirstFireAt: firstFireAt, Count: 2},
+			want: "2 reminder(s) of job standup were missed while I was unavailable, starting 2025-01-15 09:00:00 " +
+				"(UTC). All of them were skipped or excluded.",
+		},
+		{
+			name:   "over the catch up limit",
+			missed: misfire{FirstFireAt: firstFireAt, Count: 25, Resent: 10, Capped: 15},
+			want: "25 reminder(s) of job standup were missed while I was unavailable, starting 2025-01-15 09:00:00 " +
+				"(UTC). Sending 10 of them now. At most 10 are sent late, so 15 of them will not be sent.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeMisfire("standup", &tt.missed, time.UTC); got != tt.want {
+				t.Errorf("describeMisfire = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
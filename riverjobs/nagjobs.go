@@ -0,0 +1,109 @@
+package riverjobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/rs/zerolog/log"
+
+	"remembertelebot/bot"
+	"remembertelebot/db/sqlc"
+)
+
+type NagJobArgs struct {
+	DeliveryID int32 `json:"delivery_id"`
+	NagCount   int32 `json:"nag_count"`
+}
+
+func (NagJobArgs) Kind() string { return "nag" }
+
+func (NagJobArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		UniqueOpts: river.UniqueOpts{ByArgs: true},
+	}
+}
+
+type NagJobWorker struct {
+	river.WorkerDefaults[NagJobArgs]
+	botClient *bot.Client
+	queries   *sqlc.Queries
+	timeout   time.Duration
+}
+
+func NewNagJobWorker(botClient *bot.Client, queries *sqlc.Queries, timeout time.Duration) *NagJobWorker {
+	return &NagJobWorker{
+		botClient: botClient,
+		queries:   queries,
+		timeout:   timeout,
+	}
+}
+
+func (w *NagJobWorker) Timeout(*river.Job[NagJobArgs]) time.Duration {
+	return w.timeout
+}
+
+func (w *NagJobWorker) Work(ctx context.Context, job *river.Job[NagJobArgs]) error {
+	// once-off jobs are deleted as soon as they fire and recurring jobs once they finish, so a delivery is only no
+	// longer nagged when its recurring job was cancelled
+	policy, err := w.queries.GetDeliveryNagPolicy(ctx, job.Args.DeliveryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get delivery nag policy [jobArgs: %+v]: %w", job.Args, err)
+	}
+
+	if policy.AcknowledgedAt.Valid {
+		return nil
+	}
+
+	if policy.NagCount < job.Args.NagCount {
+		if err := w.sendNag(ctx, job, &policy); err != nil {
+			return fmt.Errorf("failed to send nag message [jobArgs: %+v]: %w", job.Args, err)
+		}
+
+		if _, err := w.queries.IncrementDeliveryNagCount(ctx, policy.ID); err != nil {
+			return fmt.Errorf("failed to increment delivery nag count [jobArgs: %+v]: %w", job.Args, err)
+		}
+	}
+
+	return scheduleNag(ctx, w.queries, policy.ID)
+}
+
+// sendNag re-sends the reminder of a delivery, with the same buttons, during quiet hours as the chat's policy allows.
+// The delivery keeps the message it was first sent as, so only its nag count records the re-send.
+func (w *NagJobWorker) sendNag(ctx context.Context, job *river.Job[NagJobArgs],
+	policy *sqlc.GetDeliveryNagPolicyRow) error {
+	quietHours, isQuiet, windowEnd, err := getQuietHours(ctx, w.queries, policy.TelegramChatID)
+	if err != nil {
+		return err
+	}
+
+	send := w.botClient.SendMediaMarkupMessage
+	if isQuiet {
+		switch quietHours.Policy {
+		case QuietHoursPolicyDrop:
+			log.Info().Msgf("Dropping nag during quiet hours [jobArgs: %+v].", job.Args)
+			return nil
+		case QuietHoursPolicySilent:
+			send = w.botClient.SendSilentMediaMarkupMessage
+		default:
+			log.Info().Msgf("Deferring nag until quiet hours end [jobArgs: %+v][windowEnd: %v].", job.Args, windowEnd)
+			return river.JobSnooze(time.Until(windowEnd))
+		}
+	}
+
+	var recurringJobID int32
+	if policy.IsRecurring {
+		recurringJobID = policy.JobID.Int32
+	}
+	if _, err := send(ctx, policy.TelegramChatID, bot.Media{Type: policy.MediaType, FileID: policy.MediaFileID},
+		policy.Message, NewReminderKeyboard(policy.ID, recurringJobID)); err != nil {
+		return handleSendError(ctx, w.botClient, job.JobRow, policy.TelegramChatID, err)
+	}
+	return nil
+}
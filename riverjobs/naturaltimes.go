@@ -0,0 +1,251 @@
+package riverjobs
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultHour is the time of day used when only a date is given, e.g. "tomorrow" or "25 dec".
+	defaultHour = 9
+	// maxRelativeYears bounds how far ahead a relative time may be. No unit is shorter than a minute, so an amount
+	// larger than maxRelativeAmount is always too far, whatever its unit.
+	maxRelativeYears  = 10
+	maxRelativeAmount = maxRelativeYears * 366 * 24 * 60
+)
+
+var (
+	clockRegex      = regexp.MustCompile(`^(\d{1,2})(?:[:.](\d{2}))?(?::(\d{2}))?(am|pm)?$`)
+	amountRegex     = regexp.MustCompile(`^(\d+)([a-z]*)$`)
+	dayOfMonthRegex = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?$`)
+
+	weekdays = map[string]time.Weekday{
+		"sun": time.Sunday, "sunday": time.Sunday,
+		"mon": time.Monday, "monday": time.Monday,
+		"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+		"wed": time.Wednesday, "wednesday": time.Wednesday,
+		"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+		"fri": time.Friday, "friday": time.Friday,
+		"sat": time.Saturday, "saturday": time.Saturday,
+	}
+
+	months = map[string]time.Month{
+		"jan": time.January, "january": time.January,
+		"feb": time.February, "february": time.February,
+		"mar": time.March, "march": time.March,
+		"apr": time.April, "april": time.April,
+		"may": time.May,
+		"jun": time.June, "june": time.June,
+		"jul": time.July, "july": time.July,
+		"aug": time.August, "august": time.August,
+		"sep": time.September, "sept": time.September, "september": time.September,
+		"oct": time.October, "october": time.October,
+		"nov": time.November, "november": time.November,
+		"dec": time.December, "december": time.December,
+	}
+)
+
+// naturalDay is the date part of a natural time, and how to roll it forward when the resulting time has passed.
+type naturalDay struct {
+	date  time.Time
+	years int
+	days  int
+}
+
+// ParseNaturalTime resolves a one-off time such as "in 20 minutes", "tomorrow 9am", "next tuesday 14:30" or
+// "25 dec 8pm" against now, in now's location. Times without a date are today, or tomorrow if already passed.
+// "next <weekday>" is the first such weekday after today, so on a Wednesday "next thursday" is tomorrow and
+// "next wednesday" is a week away.
+func ParseNaturalTime(text string, now time.Time) (time.Time, error) {
+	var fields []string
+	for _, field := range strings.Fields(strings.ToLower(strings.ReplaceAll(text, ",", " "))) {
+		if field != "at" && field != "on" {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return time.Time{}, errors.New("time is empty")
+	}
+
+	if fields[0] == "in" {
+		return parseRelativeTime(fields[1:], now)
+	}
+
+	day, n, err := parseNaturalDay(fields, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	hour, minute, second := defaultHour, 0, 0
+	if clock := strings.Join(fields[n:], ""); clock != "" {
+		if hour, minute, second, err = parseClock(clock); err != nil {
+			return time.Time{}, err
+		}
+	} else if n == 0 {
+		return time.Time{}, fmt.Errorf("failed to parse time [text: %s]", text)
+	}
+
+	t := time.Date(day.date.Year(), day.date.Month(), day.date.Day(), hour, minute, second, 0, now.Location())
+	if !t.After(now) && (day.years != 0 || day.days != 0) {
+		t = t.AddDate(day.years, 0, day.days)
+	}
+	return t, nil
+}
+
+// parseNaturalDay parses the leading date of fields, returning the number of fields it consumed.
+func parseNaturalDay(fields []string, now time.Time) (naturalDay, int, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch first := fields[0]; first {
+	case "today":
+		return naturalDay{date: today}, 1, nil
+	case "tomorrow", "tmr", "tmrw":
+		return naturalDay{date: today.AddDate(0, 0, 1)}, 1, nil
+	case "next":
+		if len(fields) > 1 {
+			if weekday, ok := weekdays[fields[1]]; ok {
+				days := (int(weekday)-int(today.Weekday())+6)%7 + 1
+				return naturalDay{date: today.AddDate(0, 0, days)}, 2, nil
+			}
+			if fields[1] == "week" {
+				return naturalDay{date: today.AddDate(0, 0, 7)}, 2, nil
+			}
+		}
+		return naturalDay{}, 0, fmt.Errorf("failed to parse day [text: %s]", strings.Join(fields, " "))
+	}
+
+	if weekday, ok := weekdays[fields[0]]; ok {
+		days := (int(weekday) - int(today.Weekday()) + 7) % 7
+		return naturalDay{date: today.AddDate(0, 0, days), days: 7}, 1, nil
+	}
+
+	if date, err := time.ParseInLocation(time.DateOnly, fields[0], now.Location()); err == nil {
+		return naturalDay{date: date}, 1, nil
+	}
+
+	// "25 dec [2026]" or "dec 25 [2026]"
+	if len(fields) > 1 {
+		dayText, monthText := fields[0], fields[1]
+		if _, ok := months[dayText]; ok {
+			dayText, monthText = monthText, dayText
+		}
+		month, isMonth := months[monthText]
+		match := dayOfMonthRegex.FindStringSubmatch(dayText)
+		if isMonth && match != nil {
+			dayOfMonth, _ := strconv.Atoi(match[1])
+			if len(fields) > 2 && len(fields[2]) == 4 {
+				if year, err := strconv.Atoi(fields[2]); err == nil {
+					date := time.Date(year, month, dayOfMonth, 0, 0, 0, 0, now.Location())
+					if date.Day() != dayOfMonth {
+						return naturalDay{}, 0, fmt.Errorf("invalid date [text: %s]", strings.Join(fields[:3], " "))
+					}
+					return naturalDay{date: date}, 3, nil
+				}
+			}
+			date := time.Date(today.Year(), month, dayOfMonth, 0, 0, 0, 0, now.Location())
+			if date.Day() != dayOfMonth {
+				return naturalDay{}, 0, fmt.Errorf("invalid date [text: %s]", strings.Join(fields[:2], " "))
+			}
+			return naturalDay{date: date, years: 1}, 2, nil
+		}
+	}
+
+	// no date, so the time is today or tomorrow
+	return naturalDay{date: today, days: 1}, 0, nil
+}
+
+func parseClock(text string) (int, int, int, error) {
+	switch text {
+	case "noon", "midday":
+		return 12, 0, 0, nil
+	case "midnight":
+		return 0, 0, 0, nil
+	case "morning":
+		return defaultHour, 0, 0, nil
+	}
+
+	match := clockRegex.FindStringSubmatch(text)
+	// a bare number is ambiguous, so a clock needs either minutes or am/pm
+	if match == nil || (match[2] == "" && match[4] == "") {
+		return 0, 0, 0, fmt.Errorf("failed to parse time of day [text: %s]", text)
+	}
+
+	hour, _ := strconv.Atoi(match[1])
+	minute, _ := strconv.Atoi(match[2])
+	second, _ := strconv.Atoi(match[3])
+	switch match[4] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, 0, fmt.Errorf("invalid hour [text: %s]", text)
+		}
+		hour %= 12
+		if match[4] == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 || second > 59 {
+		return 0, 0, 0, fmt.Errorf("invalid time of day [text: %s]", text)
+	}
+	return hour, minute, second, nil
+}
+
+// parseRelativeTime parses e.g. "20 minutes", "an hour", "1h 30m" or "2 days and 3 hours" after "in".
+func parseRelativeTime(fields []string, now time.Time) (time.Time, error) {
+	t := now.Truncate(time.Second)
+	isParsed := false
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		if field == "and" {
+			continue
+		}
+
+		amount, unit := 0, ""
+		switch match := amountRegex.FindStringSubmatch(field); {
+		case field == "a" || field == "an":
+			amount = 1
+		case match != nil:
+			var err error
+			if amount, err = strconv.Atoi(match[1]); err != nil || amount > maxRelativeAmount {
+				return time.Time{}, fmt.Errorf("relative time is too far ahead [amount: %s]", match[1])
+			}
+			unit = match[2]
+		default:
+			return time.Time{}, fmt.Errorf("failed to parse relative time [text: %s]", field)
+		}
+		if unit == "" {
+			if i+1 >= len(fields) {
+				return time.Time{}, fmt.Errorf("missing unit for relative time [amount: %d]", amount)
+			}
+			i++
+			unit = fields[i]
+		}
+
+		switch unit {
+		case "m", "min", "mins", "minute", "minutes":
+			t = t.Add(time.Duration(amount) * time.Minute)
+		case "h", "hr", "hrs", "hour", "hours":
+			t = t.Add(time.Duration(amount) * time.Hour)
+		case "d", "day", "days":
+			t = t.AddDate(0, 0, amount)
+		case "w", "wk", "wks", "week", "weeks":
+			t = t.AddDate(0, 0, 7*amount)
+		case "month", "months":
+			t = t.AddDate(0, amount, 0)
+		default:
+			return time.Time{}, fmt.Errorf("unknown relative time unit [unit: %s]", unit)
+		}
+		isParsed = true
+	}
+
+	if !isParsed {
+		return time.Time{}, errors.New("relative time is empty")
+	}
+	if t.After(now.AddDate(maxRelativeYears, 0, 0)) {
+		return time.Time{}, fmt.Errorf("relative time is more than %d years ahead", maxRelativeYears)
+	}
+	return t, nil
+}
@@ -0,0 +1,112 @@
+package riverjobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseNaturalTime(t *testing.T) {
+	// a Wednesday
+	now := time.Date(2025, time.January, 15, 10, 30, 45, 0, time.UTC)
+
+	tests := []struct {
+		text string
+		want time.Time
+	}{
+		{text: "in 20 minutes", want: time.Date(2025, time.January, 15, 10, 50, 45, 0, time.UTC)},
+		{text: "in an hour", want: time.Date(2025, time.January, 15, 11, 30, 45, 0, time.UTC)},
+		{text: "in 1h 30m", want: time.Date(2025, time.January, 15, 12, 0, 45, 0, time.UTC)},
+		{text: "In 2 days and 3 hours", want: time.Date(2025, time.January, 17, 13, 30, 45, 0, time.UTC)},
+		{text: "in 2 weeks", want: time.Date(2025, time.January, 29, 10, 30, 45, 0, time.UTC)},
+		{text: "in a month", want: time.Date(2025, time.February, 15, 10, 30, 45, 0, time.UTC)},
+		{text: "in 120 months", want: time.Date(2035, time.January, 15, 10, 30, 45, 0, time.UTC)},
+		{text: "tomorrow", want: time.Date(2025, time.January, 16, 9, 0, 0, 0, time.UTC)},
+		{text: "tmr at 9am", want: time.Date(2025, time.January, 16, 9, 0, 0, 0, time.UTC)},
+		{text: "today at noon", want: time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)},
+		{text: "today 12am", want: time.Date(2025, time.January, 15, 0, 0, 0, 0, time.UTC)},
+		{text: "14:30", want: time.Date(2025, time.January, 15, 14, 30, 0, 0, time.UTC)},
+		{text: "7.15pm", want: time.Date(2025, time.January, 15, 19, 15, 0, 0, time.UTC)},
+		{text: "10:30:50", want: time.Date(2025, time.January, 15, 10, 30, 50, 0, time.UTC)},
+		// times that have passed today are tomorrow
+		{text: "8:00", want: time.Date(2025, time.January, 16, 8, 0, 0, 0, time.UTC)},
+		{text: "midnight", want: time.Date(2025, time.January, 16, 0, 0, 0, 0, time.UTC)},
+		{text: "friday 5pm", want: time.Date(2025, time.January, 17, 17, 0, 0, 0, time.UTC)},
+		// a weekday that has passed today is next week
+		{text: "wed 9am", want: time.Date(2025, time.January, 22, 9, 0, 0, 0, time.UTC)},
+		{text: "wednesday 11am", want: time.Date(2025, time.January, 15, 11, 0, 0, 0, time.UTC)},
+		{text: "next tuesday 14:30", want: time.Date(2025, time.January, 21, 14, 30, 0, 0, time.UTC)},
+		{text: "next wednesday", want: time.Date(2025, time.January, 22, 9, 0, 0, 0, time.UTC)},
+		// next is the first such weekday after today, which may be tomorrow
+		{text: "next thursday", want: time.Date(2025, time.January, 16, 9, 0, 0, 0, time.UTC)},
+		{text: "next week", want: time.Date(2025, time.January, 22, 9, 0, 0, 0, time.UTC)},
+		{text: "2025-02-01 7.15pm", want: time.Date(2025, time.February, 1, 19, 15, 0, 0, time.UTC)},
+		{text: "25 dec 8pm", want: time.Date(2025, time.December, 25, 20, 0, 0, 0, time.UTC)},
+		{text: "Dec 25th, 2026", want: time.Date(2026, time.December, 25, 9, 0, 0, 0, time.UTC)},
+		// a date that has passed this year is next year
+		{text: "1 jan", want: time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC)},
+		{text: "15 jan 11:00", want: time.Date(2025, time.January, 15, 11, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParseNaturalTime(tt.text, now)
+			if err != nil {
+				t.Fatalf("ParseNaturalTime(%q) returned error: %v", tt.text, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseNaturalTime(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNaturalTimeInLocation(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation returned error: %v", err)
+	}
+
+	// clocks go forward overnight, so 9am tomorrow is only 22 hours away
+	now := time.Date(2025, time.March, 8, 10, 0, 0, 0, newYork)
+	got, err := ParseNaturalTime("tomorrow 9am", now)
+	if err != nil {
+		t.Fatalf("ParseNaturalTime returned error: %v", err)
+	}
+	if want := time.Date(2025, time.March, 9, 9, 0, 0, 0, newYork); !got.Equal(want) {
+		t.Errorf("ParseNaturalTime = %v, want %v", got, want)
+	}
+}
+
+func TestParseNaturalTimeErrors(t *testing.T) {
+	now := time.Date(2025, time.January, 15, 10, 30, 0, 0, time.UTC)
+
+	for _, text := range []string{
+		"",
+		"at",
+		"in",
+		"in 5",
+		"in 5 years",
+		"in 121 months",
+		"in 600 weeks",
+		"in 5270401 minutes",
+		"in 99999999999999999999 minutes",
+		"in soon",
+		"9",
+		"tomorrow 9",
+		"13pm",
+		"0am",
+		"25:00",
+		"9:60",
+		"next",
+		"next month",
+		"30 feb",
+		"31 apr 2026",
+		"sometime",
+	} {
+		t.Run(text, func(t *testing.T) {
+			if got, err := ParseNaturalTime(text, now); err == nil {
+				t.Errorf("ParseNaturalTime(%q) = %v, want an error", text, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package riverjobs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"remembertelebot/db/sqlc"
+)
+
+// PreviewOccurrences is how many upcoming fire times are shown when confirming and listing recurring jobs.
+const PreviewOccurrences = 3
+
+// UpcomingJob is a recurring job whose upcoming occurrences are listed. It ends at EndsAt and/or after
+// MaxOccurrences, of which OccurrenceCount have already happened, and excludes the dates of its CalendarNames.
+type UpcomingJob struct {
+	ID              int32
+	TelegramChatID  int64
+	Recurrence      Recurrence
+	CalendarNames   []string
+	EndsAt          pgtype.Timestamp
+	MaxOccurrences  pgtype.Int4
+	OccurrenceCount int64
+}
+
+// Occurrence is an upcoming occurrence of a recurring job. ExcludedBy is the calendar excluding its date, if any.
+type Occurrence struct {
+	FireAt     time.Time
+	IsSkipped  bool
+	ExcludedBy string
+}
+
+// UpcomingOccurrences lists up to n occurrences of a recurring job after now, including skipped and excluded ones.
+// Like the job itself, the list stops at the end date and once the remaining occurrences, which skipped and excluded
+// ones do not count towards, are used up. Skips and calendars are only looked up when queries is not nil.
+func UpcomingOccurrences(ctx context.Context, queries *sqlc.Queries, job UpcomingJob, loc *time.Location,
+	n int) ([]Occurrence, error) {
+	schedule, err := job.Recurrence.Schedule(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := int64(-1)
+	if job.MaxOccurrences.Valid {
+		remaining = max(int64(job.MaxOccurrences.Int32)-job.OccurrenceCount, 0)
+	}
+
+	var occurrences []Occurrence
+	fireAt := time.Now()
+	for len(occurrences) < n && remaining != 0 {
+		fireAt = schedule.Next(fireAt)
+		if fireAt.IsZero() || (job.EndsAt.Valid && !fireAt.Before(job.EndsAt.Time)) {
+			break
+		}
+
+		occurrence := Occurrence{FireAt: fireAt}
+		if queries != nil {
+			if occurrence.IsSkipped, err = IsOccurrenceSkipped(ctx, queries, job.ID, fireAt, loc); err != nil {
+				return nil, fmt.Errorf("failed to check skipped occurrence [jobID: %v][fireAt: %v]: %w", job.ID,
+					fireAt, err)
+			}
+			if occurrence.ExcludedBy, err = ExcludingCalendar(ctx, queries, job.TelegramChatID, job.CalendarNames,
+				fireAt, loc); err != nil {
+				return nil, fmt.Errorf("failed to check excluded occurrence [jobID: %v][fireAt: %v]: %w", job.ID,
+					fireAt, err)
+			}
+		}
+		if !occurrence.IsSkipped && occurrence.ExcludedBy == "" && remaining > 0 {
+			remaining--
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+	return occurrences, nil
+}
+
+func FormatOccurrences(occurrences []Occurrence, loc *time.Location) string {
+	if len(occurrences) == 0 {
+		return "None"
+	}
+
+	lines := make([]string, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		line := fmt.Sprintf("• %s", occurrence.FireAt.In(loc).Format("Mon 2006-01-02 15:04"))
+		switch {
+		case occurrence.IsSkipped:
+			line += " (skipped)"
+		case occurrence.ExcludedBy != "":
+			line += fmt.Sprintf(" (excluded by %s)", occurrence.ExcludedBy)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
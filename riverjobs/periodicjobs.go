@@ -2,34 +2,396 @@ package riverjobs
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+	"github.com/rs/zerolog/log"
 
 	"remembertelebot/bot"
+	"remembertelebot/calendars"
+	"remembertelebot/db/sqlc"
 )
 
 type PeriodicJobArgs struct {
-	Message string `json:"message"`
-	ChatID  int64  `json:"chat_id"`
+	JobID  int32     `json:"job_id" river:"unique"`
+	ChatID int64     `json:"chat_id"`
+	FireAt time.Time `json:"fire_at" river:"unique"`
 }
 
+var (
+	errJobEnded = errors.New("recurring job has ended")
+	// ErrNoNextOccurrence is returned for a schedule that never fires again, e.g. 0 9 30 2 *.
+	ErrNoNextOccurrence = errors.New("recurring job has no next occurrence")
+)
+
 func (PeriodicJobArgs) Kind() string { return "periodic" }
 
+func (PeriodicJobArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		UniqueOpts: river.UniqueOpts{ByArgs: true},
+	}
+}
+
 type PeriodicJobWorker struct {
 	river.WorkerDefaults[PeriodicJobArgs]
 	botClient *bot.Client
+	queries   *sqlc.Queries
+	pool      *pgxpool.Pool
+	timeout   time.Duration
 }
 
-func NewPeriodicJobWorker(botClient *bot.Client) *PeriodicJobWorker {
+func NewPeriodicJobWorker(botClient *bot.Client, queries *sqlc.Queries, pool *pgxpool.Pool,
+	timeout time.Duration) *PeriodicJobWorker {
 	return &PeriodicJobWorker{
 		botClient: botClient,
+		queries:   queries,
+		pool:      pool,
+		timeout:   timeout,
 	}
 }
 
+func (w *PeriodicJobWorker) Timeout(*river.Job[PeriodicJobArgs]) time.Duration {
+	return w.timeout
+}
+
 func (w *PeriodicJobWorker) Work(ctx context.Context, job *river.Job[PeriodicJobArgs]) error {
-	if err := w.botClient.SendPlainMessage(job.Args.ChatID, job.Args.Message); err != nil {
+	periodicJob, isSkipped, missed, err := w.enqueueNextOccurrence(ctx, job)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Info().Msgf("Skipping periodic job that is no longer active [jobArgs: %+v].", job.Args)
+		return nil
+	}
+	if errors.Is(err, errJobEnded) {
+		if periodicJob.RiverJobID.Int64 == job.ID {
+			w.completeJob(ctx, periodicJob)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to enqueue next periodic job [jobArgs: %+v]: %w", job.Args, err)
+	}
+
+	if missed != nil {
+		if err := notifyMisfire(ctx, w.botClient, periodicJob, missed); err != nil {
+			log.Err(err).Msgf("Unable to notify chat of missed periodic job occurrences [jobID: %v].", periodicJob.ID)
+		}
+	}
+
+	if isSkipped {
+		log.Info().Msgf("Skipping periodic job occurrence [jobArgs: %+v].", job.Args)
+	} else {
+		media := bot.Media{Type: periodicJob.MediaType, FileID: periodicJob.MediaFileID}
+		err = deliverReminder(ctx, w.botClient, w.queries, job.JobRow, periodicJob.ID, periodicJob.TelegramChatID,
+			periodicJob.Message, media, periodicJob.ID)
+	}
+	isHandled := err == nil || errors.Is(err, &river.JobCancelError{})
+	switch {
+	case !isHandled:
+	case periodicJob.FinishedAt.Valid && periodicJob.RiverJobID.Int64 == job.ID:
+		// the chain head of a finished job is its final occurrence, so the job is done once it has been handled
+		w.completeJob(ctx, periodicJob)
+	case !isSkipped && periodicJob.MaxOccurrences.Valid:
+		if err := w.finishAtOccurrenceLimit(ctx, periodicJob.ID); err != nil {
+			log.Err(err).Msgf("Unable to finish periodic job at its occurrence limit [jobID: %v].", periodicJob.ID)
+		}
+	}
+	if err != nil {
 		return fmt.Errorf("failed to send periodic message [jobArgs: %+v]: %w", job.Args, err)
 	}
 	return nil
 }
+
+func (w *PeriodicJobWorker) enqueueNextOccurrence(ctx context.Context,
+	job *river.Job[PeriodicJobArgs]) (*sqlc.GetActiveRecurringJobForUpdateRow, bool, *misfire, error) {
+	tx, err := w.pool.Begin(ctx)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	qtx := w.queries.WithTx(tx)
+	periodicJob, err := qtx.GetActiveRecurringJobForUpdate(ctx, job.Args.JobID)
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	// an occurrence due after the end date (e.g. one resumed past it), or once the occurrence limit has been sent (e.g.
+	// by catch up jobs), is never sent
+	if periodicJob.EndsAt.Valid && !job.Args.FireAt.Before(periodicJob.EndsAt.Time) ||
+		hasReachedOccurrenceLimit(periodicJob.OccurrenceCount, periodicJob.MaxOccurrences) {
+		return &periodicJob, false, nil, errJobEnded
+	}
+
+	isHead := periodicJob.RiverJobID.Int64 == job.ID && !periodicJob.FinishedAt.Valid
+	isMissed := isHead && isMisfire(job.JobRow)
+	isSkipped, err := w.isOccurrenceExcluded(ctx, qtx, &periodicJob, job.Args.FireAt)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	if isMissed && periodicJob.MisfirePolicy == MisfirePolicySkip {
+		log.Info().Msgf("Skipping periodic job occurrence missed while unavailable [jobArgs: %+v].", job.Args)
+		isSkipped = true
+	}
+
+	// the occurrences between a missed one and now are sent late too, each by its own river job
+	var missed *misfire
+	if isMissed && periodicJob.MisfirePolicy == MisfirePolicyAll {
+		if missed, err = w.catchUpTx(ctx, tx, &periodicJob, job.Args.FireAt, isSkipped); err != nil {
+			return nil, false, nil, err
+		}
+	}
+
+	// only the river job at the head of the chain may enqueue the next occurrence, so that retries and
+	// rescheduled chains never fork into duplicate reminders
+	if isHead {
+		fireAt, err := NextPeriodicFireAt(NewRecurrence(periodicJob.Schedule, periodicJob.IntervalSeconds,
+			periodicJob.AnchorAt), periodicJob.TimeZone, job.Args.FireAt)
+		if err != nil && !errors.Is(err, ErrNoNextOccurrence) {
+			return nil, false, nil, err
+		}
+
+		// this occurrence is the last one once the next is past the end date, or the schedule never fires again, so
+		// the chain ends here. Whether the occurrence limit is reached is only known once this one has been sent.
+		if fireAt.IsZero() || periodicJob.EndsAt.Valid && !fireAt.Before(periodicJob.EndsAt.Time) {
+			finishedJob, err := qtx.FinishJob(ctx, periodicJob.ID)
+			if err != nil {
+				return nil, false, nil, err
+			}
+			periodicJob.FinishedAt = finishedJob.FinishedAt
+		} else {
+			riverJobID, err := insertPeriodicJobTx(ctx, river.ClientFromContext[pgx.Tx](ctx), tx, periodicJob.ID,
+				periodicJob.TelegramChatID, fireAt)
+			if err != nil {
+				return nil, false, nil, err
+			}
+
+			if _, err := qtx.UpdateRiverJobID(ctx, sqlc.UpdateRiverJobIDParams{
+				RiverJobID: pgtype.Int8{Valid: true, Int64: *riverJobID},
+				NextFireAt: pgtype.Timestamp{Valid: true, Time: fireAt.UTC()},
+				ID:         periodicJob.ID,
+			}); err != nil {
+				return nil, false, nil, err
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, nil, err
+	}
+
+	return &periodicJob, isSkipped, missed, nil
+}
+
+// isOccurrenceExcluded reports whether the occurrence of a recurring job at fireAt was skipped, or falls on a date
+// excluded by one of its calendars.
+func (w *PeriodicJobWorker) isOccurrenceExcluded(ctx context.Context, queries *sqlc.Queries,
+	periodicJob *sqlc.GetActiveRecurringJobForUpdateRow, fireAt time.Time) (bool, error) {
+	loc := LoadLocation(periodicJob.TimeZone)
+	isSkipped, err := IsOccurrenceSkipped(ctx, queries, periodicJob.ID, fireAt, loc)
+	if err != nil || isSkipped {
+		return isSkipped, err
+	}
+
+	calendarName, err := ExcludingCalendar(ctx, queries, periodicJob.TelegramChatID, periodicJob.CalendarNames, fireAt,
+		loc)
+	if err != nil {
+		return false, err
+	}
+	return calendarName != "", nil
+}
+
+// catchUpTx enqueues the occurrences of a recurring job that were due between a missed occurrence and now, up to the
+// rest of its occurrence limit, and returns what was missed. They are sent like once-off reminders, so that they are
+// not lost when the job finishes first, while the missed occurrence itself is sent by its own river job.
+func (w *PeriodicJobWorker) catchUpTx(ctx context.Context, tx pgx.Tx,
+	periodicJob *sqlc.GetActiveRecurringJobForUpdateRow, fireAt time.Time, isSkipped bool) (*misfire, error) {
+	fireAts, count, err := missedFireAts(periodicJob, fireAt, maxCatchUpOccurrences-1)
+	if err != nil {
+		return nil, err
+	}
+
+	missed := &misfire{FirstFireAt: fireAt, Count: count + 1, Capped: count - len(fireAts)}
+	if !isSkipped {
+		missed.Resent++
+	}
+
+	qtx := w.queries.WithTx(tx)
+	for _, missedFireAt := range fireAts {
+		if hasReachedOccurrenceLimit(periodicJob.OccurrenceCount+int64(missed.Resent), periodicJob.MaxOccurrences) {
+			break
+		}
+
+		isExcluded, err := w.isOccurrenceExcluded(ctx, qtx, periodicJob, missedFireAt)
+		if err != nil {
+			return nil, err
+		}
+		if isExcluded {
+			continue
+		}
+
+		if _, err := river.ClientFromContext[pgx.Tx](ctx).InsertTx(ctx, tx, ScheduledJobArgs{
+			JobID:   periodicJob.ID,
+			Message: periodicJob.Message,
+			Media:   bot.Media{Type: periodicJob.MediaType, FileID: periodicJob.MediaFileID},
+			ChatID:  periodicJob.TelegramChatID,
+		}, nil); err != nil {
+			return nil, fmt.Errorf("failed to add catch up job tx [jobID: %v][fireAt: %v]: %w", periodicJob.ID,
+				missedFireAt, err)
+		}
+		missed.Resent++
+	}
+	return missed, nil
+}
+
+// completeJob retires a recurring job that has reached its end date or occurrence limit and tells the chat.
+func (w *PeriodicJobWorker) completeJob(ctx context.Context, periodicJob *sqlc.GetActiveRecurringJobForUpdateRow) {
+	if _, err := w.queries.DeleteJobByID(ctx, periodicJob.ID); err != nil {
+		log.Err(err).Msgf("Unable to delete finished periodic job [jobID: %v].", periodicJob.ID)
+		return
+	}
+
+	text := fmt.Sprintf("Job %s has reached its end date and will no longer be sent.", periodicJob.Name)
+	if hasReachedOccurrenceLimit(periodicJob.OccurrenceCount, periodicJob.MaxOccurrences) {
+		text = fmt.Sprintf("Job %s has been sent %d time(s) and will no longer be sent.", periodicJob.Name,
+			periodicJob.OccurrenceCount)
+	}
+	if err := w.botClient.SendPlainMessage(ctx, periodicJob.TelegramChatID, text); err != nil {
+		log.Err(err).Msgf("Unable to notify chat of finished periodic job [jobID: %v].", periodicJob.ID)
+	}
+}
+
+// finishAtOccurrenceLimit retires a recurring job once as many of its occurrences have been sent as its limit allows,
+// cancelling the next occurrence that is already pending. Only sent occurrences count, so one that was dropped or
+// failed to send does not end the job early.
+func (w *PeriodicJobWorker) finishAtOccurrenceLimit(ctx context.Context, jobID int32) error {
+	tx, err := w.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	qtx := w.queries.WithTx(tx)
+	periodicJob, err := qtx.GetActiveRecurringJobForUpdate(ctx, jobID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get active recurring job for update [jobID: %v]: %w", jobID, err)
+	}
+	if !hasReachedOccurrenceLimit(periodicJob.OccurrenceCount, periodicJob.MaxOccurrences) {
+		return nil
+	}
+
+	if periodicJob.RiverJobID.Valid {
+		if _, err := river.ClientFromContext[pgx.Tx](ctx).JobCancelTx(ctx, tx,
+			periodicJob.RiverJobID.Int64); err != nil && !errors.Is(err, rivertype.ErrNotFound) {
+			return fmt.Errorf("failed to cancel job [riverJobID: %d]: %w", periodicJob.RiverJobID.Int64, err)
+		}
+	}
+	if _, err := qtx.FinishJob(ctx, periodicJob.ID); err != nil {
+		return fmt.Errorf("failed to finish job [jobID: %v]: %w", periodicJob.ID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	w.completeJob(ctx, &periodicJob)
+	return nil
+}
+
+// hasReachedOccurrenceLimit reports whether a recurring job has been sent as many times as its limit, if any, allows.
+func hasReachedOccurrenceLimit(occurrenceCount int64, maxOccurrences pgtype.Int4) bool {
+	return maxOccurrences.Valid && occurrenceCount >= int64(maxOccurrences.Int32)
+}
+
+// IsOccurrenceSkipped reports whether the occurrence of a recurring job at fireAt, or its whole local date, was
+// skipped.
+func IsOccurrenceSkipped(ctx context.Context, queries *sqlc.Queries, jobID int32, fireAt time.Time,
+	loc *time.Location) (bool, error) {
+	localFireAt := fireAt.In(loc)
+	return queries.IsJobOccurrenceSkipped(ctx, sqlc.IsJobOccurrenceSkippedParams{
+		JobID:  jobID,
+		FireAt: pgtype.Timestamp{Valid: true, Time: fireAt.UTC()},
+		SkipDate: pgtype.Date{Valid: true, Time: time.Date(localFireAt.Year(), localFireAt.Month(), localFireAt.Day(),
+			0, 0, 0, 0, time.UTC)},
+	})
+}
+
+// ExcludingCalendar returns the name of the first of a job's calendars, bundled or the chat's own, that excludes the
+// local date of fireAt, or an empty string if none does.
+func ExcludingCalendar(ctx context.Context, queries *sqlc.Queries, chatID int64, calendarNames []string,
+	fireAt time.Time, loc *time.Location) (string, error) {
+	if len(calendarNames) == 0 {
+		return "", nil
+	}
+
+	localFireAt := fireAt.In(loc)
+	for _, name := range calendarNames {
+		if _, ok := calendars.BundledHoliday(name, localFireAt.Year(), localFireAt.Month(), localFireAt.Day()); ok {
+			return name, nil
+		}
+	}
+
+	name, err := queries.GetExcludingCalendarName(ctx, sqlc.GetExcludingCalendarNameParams{
+		TelegramChatID: chatID,
+		Column2:        calendarNames,
+		Date: pgtype.Date{Valid: true, Time: time.Date(localFireAt.Year(), localFireAt.Month(), localFireAt.Day(), 0,
+			0, 0, 0, time.UTC)},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get excluding calendar [telegramChatID: %v][fireAt: %v]: %w", chatID,
+			fireAt, err)
+	}
+	return name, nil
+}
+
+func NextPeriodicFireAt(recurrence Recurrence, timeZone string, after time.Time) (time.Time, error) {
+	schedule, err := recurrence.Schedule(LoadLocation(timeZone))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if now := time.Now(); after.Before(now) {
+		after = now
+	}
+	fireAt := schedule.Next(after)
+	if fireAt.IsZero() {
+		return time.Time{}, ErrNoNextOccurrence
+	}
+	return fireAt, nil
+}
+
+func insertPeriodicJobTx(ctx context.Context, client *river.Client[pgx.Tx], tx pgx.Tx, jobID int32, chatID int64,
+	fireAt time.Time) (*int64, error) {
+	// river runs a job without a scheduled time straight away
+	if fireAt.IsZero() {
+		return nil, ErrNoNextOccurrence
+	}
+
+	job, err := client.InsertTx(ctx, tx, PeriodicJobArgs{
+		JobID:  jobID,
+		ChatID: chatID,
+		FireAt: fireAt,
+	}, &river.InsertOpts{
+		ScheduledAt: fireAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add periodic job tx [jobID: %v][fireAt: %s]: %w", jobID, fireAt.String(),
+			err)
+	}
+
+	return &job.Job.ID, nil
+}
@@ -0,0 +1,69 @@
+package riverjobs
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestHasReachedOccurrenceLimit(t *testing.T) {
+	threeTimes := pgtype.Int4{Valid: true, Int32: 3}
+
+	tests := []struct {
+		name           string
+		statuses       []string
+		maxOccurrences pgtype.Int4
+		want           bool
+	}{
+		{
+			name:           "unbounded",
+			statuses:       []string{DeliveryStatusSent, DeliveryStatusSent, DeliveryStatusSent},
+			maxOccurrences: pgtype.Int4{},
+		},
+		{
+			name:           "below the limit",
+			statuses:       []string{DeliveryStatusSent, DeliveryStatusSent},
+			maxOccurrences: threeTimes,
+		},
+		{
+			name:           "at the limit",
+			statuses:       []string{DeliveryStatusSent, DeliveryStatusSent, DeliveryStatusSent},
+			maxOccurrences: threeTimes,
+			want:           true,
+		},
+		{
+			name:           "a dropped occurrence is not sent",
+			statuses:       []string{DeliveryStatusSent, DeliveryStatusDropped, DeliveryStatusSent},
+			maxOccurrences: threeTimes,
+		},
+		{
+			name:           "a failed occurrence is not sent",
+			statuses:       []string{DeliveryStatusFailed, DeliveryStatusSent, DeliveryStatusSent},
+			maxOccurrences: threeTimes,
+		},
+		{
+			name: "sent after a dropped occurrence",
+			statuses: []string{DeliveryStatusSent, DeliveryStatusDropped, DeliveryStatusSent, DeliveryStatusFailed,
+				DeliveryStatusSent},
+			maxOccurrences: threeTimes,
+			want:           true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// occurrence_count only goes up when a delivery is sent
+			var occurrenceCount int64
+			for _, status := range tt.statuses {
+				if status == DeliveryStatusSent {
+					occurrenceCount++
+				}
+			}
+
+			if got := hasReachedOccurrenceLimit(occurrenceCount, tt.maxOccurrences); got != tt.want {
+				t.Errorf("hasReachedOccurrenceLimit(%d, %+v) = %v, want %v", occurrenceCount, tt.maxOccurrences, got,
+					tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package riverjobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+
+	"remembertelebot/config"
+)
+
+// Jobs inserted before each kind had its own queue are still in river.QueueDefault, which keeps a few workers to
+// drain them.
+const legacyQueueMaxWorkers = 5
+
+// newQueues returns the queue of each job kind, keyed by kind. A kind's queue is named after the kind.
+func newQueues(envCfg config.EnvConfig) (map[string]config.QueueConfig, error) {
+	queues := map[string]config.QueueConfig{
+		ScheduledJobArgs{}.Kind(): withQueueDefaults(envCfg.ScheduledQueue, config.QueueConfig{
+			MaxWorkers: 50,
+			Timeout:    time.Minute,
+		}),
+		PeriodicJobArgs{}.Kind(): withQueueDefaults(envCfg.PeriodicQueue, config.QueueConfig{
+			MaxWorkers: 50,
+			Timeout:    time.Minute,
+		}),
+		SnoozeJobArgs{}.Kind(): withQueueDefaults(envCfg.SnoozeQueue, config.QueueConfig{
+			MaxWorkers: 20,
+			Timeout:    time.Minute,
+		}),
+		NagJobArgs{}.Kind(): withQueueDefaults(envCfg.NagQueue, config.QueueConfig{
+			MaxWorkers: 10,
+			Timeout:    time.Minute,
+		}),
+	}
+
+	for kind, queue := range queues {
+		if queue.MaxWorkers < 1 || queue.MaxWorkers > river.QueueNumWorkersMax {
+			return nil, fmt.Errorf("max workers must be between 1 and %d [kind: %s][maxWorkers: %v]",
+				river.QueueNumWorkersMax, kind, queue.MaxWorkers)
+		}
+		if queue.Timeout < 0 {
+			return nil, fmt.Errorf("timeout cannot be negative [kind: %s][timeout: %v]", kind, queue.Timeout)
+		}
+	}
+	return queues, nil
+}
+
+func withQueueDefaults(queue, defaults config.QueueConfig) config.QueueConfig {
+	if queue.MaxWorkers == 0 {
+		queue.MaxWorkers = defaults.MaxWorkers
+	}
+	if queue.Timeout == 0 {
+		queue.Timeout = defaults.Timeout
+	}
+	return queue
+}
+
+// riverQueues returns the River queues to work: one per job kind, plus the legacy default queue.
+func riverQueues(queues map[string]config.QueueConfig) map[string]river.QueueConfig {
+	riverQueues := map[string]river.QueueConfig{
+		river.QueueDefault: {MaxWorkers: legacyQueueMaxWorkers},
+	}
+	for kind, queue := range queues {
+		riverQueues[kind] = river.QueueConfig{MaxWorkers: queue.MaxWorkers}
+	}
+	return riverQueues
+}
+
+// maxQueueTimeout is the longest a job of any kind may run, which River needs to know so that it does not rescue
+// jobs that are still running.
+func maxQueueTimeout(queues map[string]config.QueueConfig) time.Duration {
+	var timeout time.Duration
+	for _, queue := range queues {
+		timeout = max(timeout, queue.Timeout)
+	}
+	return timeout
+}
+
+// queueMiddleware routes every inserted job to the queue of its kind, wherever it is inserted from, so that callers
+// do not need to pass insert options.
+type queueMiddleware struct {
+	river.JobInsertMiddlewareDefaults
+	queues map[string]config.QueueConfig
+}
+
+func (m *queueMiddleware) InsertMany(ctx context.Context, manyParams []*rivertype.JobInsertParams,
+	doInner func(context.Context) ([]*rivertype.JobInsertResult, error)) ([]*rivertype.JobInsertResult, error) {
+	for _, params := range manyParams {
+		if _, ok := m.queues[params.Kind]; !ok || params.Queue != river.QueueDefault {
+			continue
+		}
+		params.Queue = params.Kind
+	}
+	return doInner(ctx)
+}
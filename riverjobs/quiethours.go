@@ -0,0 +1,104 @@
+package riverjobs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// What happens to a reminder that fires during a chat's quiet hours.
+const (
+	QuietHoursPolicyDefer  = "defer"
+	QuietHoursPolicyDrop   = "drop"
+	QuietHoursPolicySilent = "silent"
+)
+
+// QuietHours is a daily window in a chat's time zone, from Start to End minutes after midnight. A window whose End
+// is before its Start spans midnight, e.g. 22:00-07:00.
+type QuietHours struct {
+	Start  int
+	End    int
+	Policy string
+}
+
+func NewQuietHours(start pgtype.Int4, end pgtype.Int4, policy string) (QuietHours, bool) {
+	if !start.Valid || !end.Valid {
+		return QuietHours{}, false
+	}
+	return QuietHours{Start: int(start.Int32), End: int(end.Int32), Policy: policy}, true
+}
+
+// ParseQuietHours parses a window such as "22:00-07:00" and an optional policy, which defaults to deferring.
+func ParseQuietHours(text string) (QuietHours, error) {
+	fields := strings.Fields(strings.ToLower(text))
+	if len(fields) < 1 || len(fields) > 2 {
+		return QuietHours{}, errors.New("please input quiet hours as HH:MM-HH:MM followed by defer, drop or silent")
+	}
+
+	startText, endText, ok := strings.Cut(fields[0], "-")
+	if !ok {
+		return QuietHours{}, errors.New("please input quiet hours as HH:MM-HH:MM")
+	}
+	start, err := time.Parse("15:04", startText)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("failed to parse quiet hours start [start: %s]: %w", startText, err)
+	}
+	end, err := time.Parse("15:04", endText)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("failed to parse quiet hours end [end: %s]: %w", endText, err)
+	}
+
+	quietHours := QuietHours{
+		Start:  start.Hour()*60 + start.Minute(),
+		End:    end.Hour()*60 + end.Minute(),
+		Policy: QuietHoursPolicyDefer,
+	}
+	if quietHours.Start == quietHours.End {
+		return QuietHours{}, errors.New("quiet hours must start and end at different times")
+	}
+
+	if len(fields) == 2 {
+		switch fields[1] {
+		case QuietHoursPolicyDefer, QuietHoursPolicyDrop, QuietHoursPolicySilent:
+			quietHours.Policy = fields[1]
+		default:
+			return QuietHours{}, errors.New("quiet hours policy must be defer, drop or silent")
+		}
+	}
+	return quietHours, nil
+}
+
+// WindowEnd reports whether t falls within the quiet hours in loc and, if so, when that window ends.
+func (q QuietHours) WindowEnd(t time.Time, loc *time.Location) (time.Time, bool) {
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	isQuiet := q.Start <= minute && minute < q.End
+	if q.End < q.Start {
+		isQuiet = minute >= q.Start || minute < q.End
+	}
+	if !isQuiet {
+		return time.Time{}, false
+	}
+
+	end := time.Date(local.Year(), local.Month(), local.Day(), q.End/60, q.End%60, 0, 0, loc)
+	if !end.After(local) {
+		end = time.Date(local.Year(), local.Month(), local.Day()+1, q.End/60, q.End%60, 0, 0, loc)
+	}
+	return end, true
+}
+
+func (q QuietHours) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d (%s)", q.Start/60, q.Start%60, q.End/60, q.End%60, q.Policy)
+}
+
+func (q QuietHours) StartInt4() pgtype.Int4 {
+	return pgtype.Int4{Valid: true, Int32: int32(q.Start)}
+}
+
+func (q QuietHours) EndInt4() pgtype.Int4 {
+	return pgtype.Int4{Valid: true, Int32: int32(q.End)}
+}
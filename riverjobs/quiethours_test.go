@@ -0,0 +1,131 @@
+package riverjobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestParseQuietHours(t *testing.T) {
+	tests := []struct {
+		text string
+		want QuietHours
+	}{
+		{text: "22:00-07:00", want: QuietHours{Start: 22 * 60, End: 7 * 60, Policy: QuietHoursPolicyDefer}},
+		{text: " 13:30-14:15 DROP ", want: QuietHours{Start: 13*60 + 30, End: 14*60 + 15, Policy: QuietHoursPolicyDrop}},
+		{text: "23:00-00:00 silent", want: QuietHours{Start: 23 * 60, End: 0, Policy: QuietHoursPolicySilent}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParseQuietHours(tt.text)
+			if err != nil {
+				t.Fatalf("ParseQuietHours(%q) returned error: %v", tt.text, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseQuietHours(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuietHoursErrors(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"22:00",
+		"22:00 07:00",
+		"22:00-07:00 defer now",
+		"24:00-07:00",
+		"22:00-7am",
+		"22:00-22:00",
+		"22:00-07:00 mute",
+	} {
+		t.Run(text, func(t *testing.T) {
+			if got, err := ParseQuietHours(text); err == nil {
+				t.Errorf("ParseQuietHours(%q) = %+v, want an error", text, got)
+			}
+		})
+	}
+}
+
+func TestQuietHoursWindowEnd(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	overnight := QuietHours{Start: 22 * 60, End: 7 * 60}
+	lunch := QuietHours{Start: 12 * 60, End: 13*60 + 30}
+
+	tests := []struct {
+		name       string
+		quietHours QuietHours
+		t          time.Time
+		want       time.Time
+		wantQuiet  bool
+	}{
+		{
+			name:       "before the window",
+			quietHours: lunch,
+			t:          time.Date(2025, time.January, 15, 11, 59, 0, 0, loc),
+		},
+		{
+			name:       "at the start",
+			quietHours: lunch,
+			t:          time.Date(2025, time.January, 15, 12, 0, 0, 0, loc),
+			want:       time.Date(2025, time.January, 15, 13, 30, 0, 0, loc),
+			wantQuiet:  true,
+		},
+		{
+			name:       "at the end",
+			quietHours: lunch,
+			t:          time.Date(2025, time.January, 15, 13, 30, 0, 0, loc),
+		},
+		{
+			name:       "overnight before midnight",
+			quietHours: overnight,
+			t:          time.Date(2025, time.January, 31, 23, 0, 0, 0, loc),
+			want:       time.Date(2025, time.February, 1, 7, 0, 0, 0, loc),
+			wantQuiet:  true,
+		},
+		{
+			name:       "overnight after midnight",
+			quietHours: overnight,
+			t:          time.Date(2025, time.January, 15, 6, 59, 0, 0, loc),
+			want:       time.Date(2025, time.January, 15, 7, 0, 0, 0, loc),
+			wantQuiet:  true,
+		},
+		{
+			name:       "overnight during the day",
+			quietHours: overnight,
+			t:          time.Date(2025, time.January, 15, 7, 0, 0, 0, loc),
+		},
+		{
+			// 21:30 UTC is 23:30 in UTC+2
+			name:       "in the chat's time zone",
+			quietHours: overnight,
+			t:          time.Date(2025, time.January, 15, 21, 30, 0, 0, time.UTC),
+			want:       time.Date(2025, time.January, 16, 7, 0, 0, 0, loc),
+			wantQuiet:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, isQuiet := tt.quietHours.WindowEnd(tt.t, loc)
+			if isQuiet != tt.wantQuiet || !got.Equal(tt.want) {
+				t.Errorf("WindowEnd(%v) = %v, %v, want %v, %v", tt.t, got, isQuiet, tt.want, tt.wantQuiet)
+			}
+		})
+	}
+}
+
+func TestNewQuietHours(t *testing.T) {
+	want := QuietHours{Start: 22 * 60, End: 7 * 60, Policy: QuietHoursPolicyDrop}
+	if got, ok := NewQuietHours(want.StartInt4(), want.EndInt4(), want.Policy); !ok || got != want {
+		t.Errorf("NewQuietHours = %+v, %v, want %+v, true", got, ok, want)
+	}
+	if _, ok := NewQuietHours(pgtype.Int4{}, want.EndInt4(), want.Policy); ok {
+		t.Error("NewQuietHours without a start returned quiet hours")
+	}
+	if got, want := want.String(), "22:00-07:00 (drop)"; got != want {
+		t.Errorf("String = %q, want %q", got, want)
+	}
+}
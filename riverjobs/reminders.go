@@ -0,0 +1,63 @@
+package riverjobs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	DoneQueryData             = "done"
+	SnoozeQueryDataPrefix     = "snooze-"
+	Snooze10MinutesQueryData  = "snooze-10m"
+	Snooze1HourQueryData      = "snooze-1h"
+	SnoozeTomorrowQueryData   = "snooze-tomorrow"
+	SnoozeCustomQueryData     = "snooze-custom"
+	SkipNextQueryData         = "skip-next"
+	SnoozeTomorrowMorningHour = 9
+)
+
+func NewReminderKeyboard(deliveryID int32, recurringJobID int32) tgbotapi.InlineKeyboardMarkup {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Done", reminderQueryData(DoneQueryData, deliveryID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Snooze 10 min", reminderQueryData(Snooze10MinutesQueryData,
+				deliveryID)),
+			tgbotapi.NewInlineKeyboardButtonData("Snooze 1 h", reminderQueryData(Snooze1HourQueryData, deliveryID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Tomorrow morning", reminderQueryData(SnoozeTomorrowQueryData,
+				deliveryID)),
+			tgbotapi.NewInlineKeyboardButtonData("Custom", reminderQueryData(SnoozeCustomQueryData, deliveryID)),
+		),
+	)
+	if recurringJobID != 0 {
+		// unlike the other buttons, this one carries the ID of the recurring job
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏭ Skip next", reminderQueryData(SkipNextQueryData, recurringJobID)),
+		))
+	}
+	return keyboard
+}
+
+// ParseReminderQueryData splits the query data of a reminder button into its action and delivery ID. Buttons sent
+// before deliveries were tracked carry no delivery ID, in which case 0 is returned.
+func ParseReminderQueryData(data string) (string, int32) {
+	i := strings.LastIndex(data, "-")
+	if i < 0 {
+		return data, 0
+	}
+	deliveryID, err := strconv.ParseInt(data[i+1:], 10, 32)
+	if err != nil {
+		return data, 0
+	}
+	return data[:i], int32(deliveryID)
+}
+
+func reminderQueryData(action string, deliveryID int32) string {
+	return fmt.Sprintf("%s-%d", action, deliveryID)
+}
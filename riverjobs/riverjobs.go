@@ -2,8 +2,12 @@ package riverjobs
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -12,7 +16,6 @@ import (
 	"github.com/riverqueue/river"
 	"github.com/riverqueue/river/riverdriver/riverpgxv5"
 	"github.com/riverqueue/river/rivertype"
-	"github.com/robfig/cron/v3"
 	"github.com/rs/zerolog/log"
 
 	"remembertelebot/bot"
@@ -20,31 +23,47 @@ import (
 	"remembertelebot/db/sqlc"
 )
 
+var pendingJobStates = []rivertype.JobState{
+	rivertype.JobStateAvailable,
+	rivertype.JobStatePending,
+	rivertype.JobStateRetryable,
+	rivertype.JobStateRunning,
+	rivertype.JobStateScheduled,
+}
+
 type Client struct {
 	Client                 *river.Client[pgx.Tx]
 	CancelCompletedChannel func()
+	botClient              *bot.Client
 	queries                *sqlc.Queries
+	pool                   *pgxpool.Pool
 }
 
 func NewClient(envCfg config.EnvConfig, pool *pgxpool.Pool, botClient *bot.Client, queries *sqlc.Queries) *Client {
-	client, completedChannel, cancelCompletedChannel := setupRiverClient(envCfg, pool, botClient)
+	client, completedChannel, cancelCompletedChannel := setupRiverClient(envCfg, pool, botClient, queries)
 
 	riverClient := &Client{
 		Client:                 client,
 		CancelCompletedChannel: cancelCompletedChannel,
+		botClient:              botClient,
 		queries:                queries,
+		pool:                   pool,
 	}
 
 	go riverClient.processJobCompletedEvent(completedChannel)
 
 	riverClient.addPeriodicJobsOnStartUp()
+	riverClient.recoverScheduledJobsOnStartUp()
 
 	return riverClient
 }
 
-func (c *Client) AddScheduledJobTx(tx pgx.Tx, message string, chatID int64, schedule time.Time) (*int64, error) {
+func (c *Client) AddScheduledJobTx(tx pgx.Tx, jobID int32, message string, media bot.Media, chatID int64,
+	schedule time.Time) (*int64, error) {
 	job, err := c.Client.InsertTx(context.Background(), tx, ScheduledJobArgs{
+		JobID:   jobID,
 		Message: message,
+		Media:   media,
 		ChatID:  chatID,
 	}, &river.InsertOpts{
 		ScheduledAt: schedule,
@@ -58,37 +77,383 @@ func (c *Client) AddScheduledJobTx(tx pgx.Tx, message string, chatID int64, sche
 	return &job.Job.ID, nil
 }
 
-func (c *Client) CancelScheduledJob(jobID int64) error {
-	if _, err := c.Client.JobCancel(context.Background(), jobID); err != nil {
-		return fmt.Errorf("failed to cancel scheduled job [jobID: %d]: %w", jobID, err)
+// AddPeriodicJobTx enqueues the first occurrence of a recurring job, returning its river job ID and when it fires.
+func (c *Client) AddPeriodicJobTx(tx pgx.Tx, jobID int32, chatID int64, recurrence Recurrence,
+	timeZone string) (*int64, time.Time, error) {
+	fireAt, err := NextPeriodicFireAt(recurrence, timeZone, time.Now())
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	riverJobID, err := insertPeriodicJobTx(context.Background(), c.Client, tx, jobID, chatID, fireAt)
+	return riverJobID, fireAt, err
+}
+
+func (c *Client) AddSnoozeJob(deliveryID int32, message string, media bot.Media, chatID int64,
+	schedule time.Time) (*int64, error) {
+	job, err := c.Client.Insert(context.Background(), SnoozeJobArgs{
+		DeliveryID: deliveryID,
+		Message:    message,
+		Media:      media,
+		ChatID:     chatID,
+	}, &river.InsertOpts{
+		ScheduledAt: schedule,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add snooze job [message: %s][schedule: %s]: %w", message, schedule.String(),
+			err)
+	}
+
+	return &job.Job.ID, nil
+}
+
+// CancelJob cancels the pending river job of one of the chat's jobs and deletes it, like CancelJobs, returning its
+// name.
+func (c *Client) CancelJob(telegramChatID int64, jobID int32) (string, error) {
+	cancellations, err := c.CancelJobs(telegramChatID, []int32{jobID})
+	if err != nil {
+		return "", err
+	}
+	return cancellations[0].Name, cancellations[0].Err
+}
+
+// JobCancellation is the outcome of cancelling one of the jobs passed to CancelJobs, which failed if Err is set.
+type JobCancellation struct {
+	JobID int32
+	Name  string
+	Err   error
+}
+
+// CancelJobs cancels the pending river jobs of the chat's given jobs and deletes them in one transaction. Cancelling
+// the pending occurrence of a recurring job ends its chain, so nothing else is left to remove. Each job is cancelled
+// within its own savepoint, so a job that cannot be cancelled is reported without undoing the others.
+func (c *Client) CancelJobs(telegramChatID int64, jobIDs []int32) ([]JobCancellation, error) {
+	ctx := context.Background()
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	cancellations := make([]JobCancellation, 0, len(jobIDs))
+	for _, jobID := range jobIDs {
+		name, err := c.cancelJobTx(ctx, tx, telegramChatID, jobID)
+		cancellations = append(cancellations, JobCancellation{JobID: jobID, Name: name, Err: err})
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return cancellations, nil
+}
+
+// cancelJobTx cancels and deletes one job within a savepoint of tx, returning its name.
+func (c *Client) cancelJobTx(ctx context.Context, tx pgx.Tx, telegramChatID int64, jobID int32) (string, error) {
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = savepoint.Rollback(ctx)
+	}()
+
+	qtx := c.queries.WithTx(savepoint)
+	job, err := qtx.GetJobByID(ctx, jobID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", errors.New("job not found")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get job [jobID: %v]: %w", jobID, err)
+	}
+	if job.TelegramChatID != telegramChatID {
+		log.Error().Msgf("Unauthorized job cancellation [telegramChatID: %v][job: %+v].", telegramChatID, job)
+		return job.Name, errors.New("you can only cancel your own jobs")
+	}
+
+	if job.RiverJobID.Valid {
+		if _, err := c.Client.JobCancelTx(ctx, savepoint, job.RiverJobID.Int64); err != nil && !errors.Is(err,
+			rivertype.ErrNotFound) {
+			return job.Name, fmt.Errorf("failed to cancel job [riverJobID: %d]: %w", job.RiverJobID.Int64, err)
+		}
+	}
+
+	if _, err := qtx.DeleteJobByID(ctx, job.ID); err != nil {
+		return job.Name, fmt.Errorf("failed to delete job [jobID: %v]: %w", job.ID, err)
+	}
+	return job.Name, savepoint.Commit(ctx)
+}
+
+// UpdateJob changes the name, message and schedule of a job and replaces its pending river job where needed, all in
+// one transaction so that the job keeps its ID and is never left without (or with two) pending occurrences.
+func (c *Client) UpdateJob(jobID int32, name string, message string, schedule string, intervalSeconds pgtype.Int4,
+	anchorAt pgtype.Timestamp) (*sqlc.Job, error) {
+	ctx := context.Background()
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	qtx := c.queries.WithTx(tx)
+	previousJob, err := qtx.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job [jobID: %v]: %w", jobID, err)
+	}
+
+	job, err := qtx.UpdateJobDetails(ctx, sqlc.UpdateJobDetailsParams{
+		Name:            name,
+		Message:         message,
+		Schedule:        schedule,
+		IntervalSeconds: intervalSeconds,
+		AnchorAt:        anchorAt,
+		ID:              jobID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update job details [jobID: %v]: %w", jobID, err)
+	}
+
+	if job.IsRecurring {
+		// paused jobs are scheduled again when they are resumed
+		isRescheduled := job.Schedule != previousJob.Schedule || job.AnchorAt != previousJob.AnchorAt
+		if isRescheduled && !job.PausedAt.Valid {
+			if err := c.schedulePeriodicJobTx(ctx, tx, job.ID, true, false); err != nil {
+				return nil, fmt.Errorf("failed to reschedule periodic job [jobID: %v]: %w", jobID, err)
+			}
+		}
+	} else if job.Message != previousJob.Message || job.Schedule != previousJob.Schedule {
+		// the message of a once-off job is part of its river job args, so its river job is replaced too
+		if job.RiverJobID.Valid {
+			if _, err := c.Client.JobCancelTx(ctx, tx, job.RiverJobID.Int64); err != nil && !errors.Is(err,
+				rivertype.ErrNotFound) {
+				return nil, fmt.Errorf("failed to cancel job [riverJobID: %d]: %w", job.RiverJobID.Int64, err)
+			}
+		}
+
+		fireAt, err := time.Parse(time.DateTime, job.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse once-off schedule [schedule: %s]: %w", job.Schedule, err)
+		}
+		riverJobID, err := c.AddScheduledJobTx(tx, job.ID, job.Message, bot.Media{Type: job.MediaType,
+			FileID: job.MediaFileID}, job.TelegramChatID, fireAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if job, err = qtx.UpdateRiverJobID(ctx, sqlc.UpdateRiverJobIDParams{
+			RiverJobID: pgtype.Int8{Valid: true, Int64: *riverJobID},
+			NextFireAt: pgtype.Timestamp{Valid: true, Time: fireAt.UTC()},
+			ID:         job.ID,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// PauseJob marks a recurring job as paused and cancels its pending occurrence, so that nothing is sent until it
+// is resumed.
+func (c *Client) PauseJob(jobID int32) (*sqlc.Job, error) {
+	ctx := context.Background()
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	job, err := c.queries.WithTx(tx).PauseJob(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pause job [jobID: %v]: %w", jobID, err)
+	}
+
+	if job.RiverJobID.Valid {
+		if _, err := c.Client.JobCancelTx(ctx, tx, job.RiverJobID.Int64); err != nil && !errors.Is(err,
+			rivertype.ErrNotFound) {
+			return nil, fmt.Errorf("failed to cancel job [riverJobID: %d]: %w", job.RiverJobID.Int64, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ResumeJob clears the paused state of a recurring job and enqueues its next occurrence from now on.
+func (c *Client) ResumeJob(jobID int32) (*sqlc.Job, error) {
+	ctx := context.Background()
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	job, err := c.queries.WithTx(tx).ResumeJob(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resume job [jobID: %v]: %w", jobID, err)
+	}
+
+	if err := c.schedulePeriodicJobTx(ctx, tx, job.ID, false, false); err != nil {
+		return nil, fmt.Errorf("failed to schedule resumed job [jobID: %v]: %w", jobID, err)
 	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (c *Client) ReschedulePeriodicJobs(telegramChatID int64) error {
+	jobs, err := c.queries.GetActiveRecurringJobsByTelegramChatID(context.Background(), telegramChatID)
+	if err != nil {
+		return fmt.Errorf("failed to get active recurring jobs [telegramChatID: %v]: %w", telegramChatID, err)
+	}
+
+	for _, job := range jobs {
+		if err := c.schedulePeriodicJob(job.ID, true, false); err != nil {
+			return fmt.Errorf("failed to reschedule periodic job [job: %+v]: %w", job, err)
+		}
+	}
+
 	return nil
 }
 
-func (c *Client) AddPeriodicJob(message string, chatID int64, cronTab string) (*int64, error) {
-	schedule, err := cron.ParseStandard(cronTab)
+// schedulePeriodicJob enqueues the next occurrence of a recurring job unless one is already pending (or force is set),
+// while holding a row lock so that concurrently starting instances only ever enqueue it once. With catchUp set, the
+// first occurrence missed since the job last fired is enqueued instead, if there is one.
+func (c *Client) schedulePeriodicJob(jobID int32, force bool, catchUp bool) error {
+	ctx := context.Background()
+	tx, err := c.pool.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse cron tab [cronTab: %s]: %w", cronTab, err)
+		return err
 	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
 
-	jobHandle := c.Client.PeriodicJobs().Add(river.NewPeriodicJob(
-		schedule,
-		func() (river.JobArgs, *river.InsertOpts) {
-			return PeriodicJobArgs{
-				Message: message,
-				ChatID:  chatID,
-			}, nil
-		},
-		nil,
-	))
+	if err := c.schedulePeriodicJobTx(ctx, tx, jobID, force, catchUp); err != nil {
+		return err
+	}
 
-	jobHandleInt64 := int64(jobHandle)
-	return &jobHandleInt64, nil
+	return tx.Commit(ctx)
 }
 
-func (c *Client) CancelPeriodicJob(jobHandle int64) {
-	jobHandleInt := int(jobHandle)
-	c.Client.PeriodicJobs().Remove(rivertype.PeriodicJobHandle(jobHandleInt))
+func (c *Client) schedulePeriodicJobTx(ctx context.Context, tx pgx.Tx, jobID int32, force bool, catchUp bool) error {
+	qtx := c.queries.WithTx(tx)
+	job, err := qtx.GetActiveRecurringJobForUpdate(ctx, jobID)
+	if err != nil {
+		return err
+	}
+
+	// the final occurrence of a bounded job is already pending and must not be replaced
+	if job.FinishedAt.Valid {
+		return nil
+	}
+
+	isPending, err := c.hasPendingPeriodicJobTx(ctx, tx, job.ID, job.RiverJobID)
+	if err != nil {
+		return err
+	}
+	if isPending && !force {
+		return nil
+	}
+	if isPending {
+		if _, err := c.Client.JobCancelTx(ctx, tx, job.RiverJobID.Int64); err != nil {
+			return err
+		}
+	}
+
+	recurrence := NewRecurrence(job.Schedule, job.IntervalSeconds, job.AnchorAt)
+	fireAt, err := NextPeriodicFireAt(recurrence, job.TimeZone, time.Now())
+	if errors.Is(err, ErrNoNextOccurrence) {
+		// there is no occurrence left to enqueue, so the job is finished
+		log.Info().Msgf("Deleting periodic job without a next occurrence [jobID: %v][schedule: %s].", job.ID,
+			job.Schedule)
+		_, err = qtx.DeleteJobByID(ctx, job.ID)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	riverJobID, fireAt, err := c.insertMissedPeriodicJobTx(ctx, tx, &job, recurrence, catchUp, fireAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = qtx.UpdateRiverJobID(ctx, sqlc.UpdateRiverJobIDParams{
+		RiverJobID: pgtype.Int8{Valid: true, Int64: *riverJobID},
+		NextFireAt: pgtype.Timestamp{Valid: true, Time: fireAt.UTC()},
+		ID:         job.ID,
+	})
+	return err
+}
+
+// insertMissedPeriodicJobTx enqueues the first occurrence of a recurring job missed since it last fired, if catchUp is
+// set and there is one, and otherwise its next occurrence at fireAt, returning its river job ID and fire time. The
+// missed occurrence runs late straight away, and its worker applies the job's misfire policy.
+func (c *Client) insertMissedPeriodicJobTx(ctx context.Context, tx pgx.Tx, job *sqlc.GetActiveRecurringJobForUpdateRow,
+	recurrence Recurrence, catchUp bool, fireAt time.Time) (*int64, time.Time, error) {
+	if catchUp && job.LastFiredAt.Valid {
+		schedule, err := recurrence.Schedule(LoadLocation(job.TimeZone))
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+
+		if missedFireAt := schedule.Next(job.LastFiredAt.Time); !missedFireAt.IsZero() && missedFireAt.Before(fireAt) {
+			riverJobID, err := insertPeriodicJobTx(ctx, c.Client, tx, job.ID, job.TelegramChatID, missedFireAt)
+			if err != nil {
+				return nil, time.Time{}, err
+			}
+
+			// an occurrence that already ran without firing, e.g. a skipped one, is not enqueued again
+			isPending, err := c.hasPendingPeriodicJobTx(ctx, tx, job.ID, pgtype.Int8{Valid: true, Int64: *riverJobID})
+			if err != nil {
+				return nil, time.Time{}, err
+			}
+			if isPending {
+				return riverJobID, missedFireAt, nil
+			}
+		}
+	}
+
+	riverJobID, err := insertPeriodicJobTx(ctx, c.Client, tx, job.ID, job.TelegramChatID, fireAt)
+	return riverJobID, fireAt, err
+}
+
+func (c *Client) hasPendingPeriodicJobTx(ctx context.Context, tx pgx.Tx, jobID int32, riverJobID pgtype.Int8) (bool,
+	error) {
+	if !riverJobID.Valid {
+		return false, nil
+	}
+
+	riverJob, err := c.Client.JobGetTx(ctx, tx, riverJobID.Int64)
+	if errors.Is(err, rivertype.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// river job IDs saved before recurring jobs were persisted are in-memory handles that may point at any job
+	var args PeriodicJobArgs
+	if riverJob.Kind != args.Kind() {
+		return false, nil
+	}
+	if err := json.Unmarshal(riverJob.EncodedArgs, &args); err != nil || args.JobID != jobID {
+		return false, nil
+	}
+
+	return slices.Contains(pendingJobStates, riverJob.State), nil
 }
 
 func (c *Client) addPeriodicJobsOnStartUp() {
@@ -99,21 +464,93 @@ func (c *Client) addPeriodicJobsOnStartUp() {
 	}
 
 	for _, job := range jobs {
-		riverJobID, err := c.AddPeriodicJob(job.Message, job.TelegramChatID, job.Schedule)
-		if err != nil {
-			log.Err(err).Msgf("Unable to add periodic job on service start [job: %+v].", job)
-			continue
+		if err := c.schedulePeriodicJob(job.ID, false, true); err != nil {
+			log.Err(err).Msgf("Unable to schedule periodic job on service start [job: %+v].", job)
 		}
+	}
 
-		if _, err := c.queries.UpdateRiverJobID(context.Background(), sqlc.UpdateRiverJobIDParams{
-			RiverJobID: pgtype.Int8{Valid: true, Int64: *riverJobID},
-			ID:         job.ID,
-		}); err != nil {
-			log.Err(err).Msgf("Unable to update river job ID [jobID: %v][riverJobID: %v].", job.ID, *riverJobID)
+	log.Info().Msgf("Checked %v periodic job(s) on service start up.", len(jobs))
+}
+
+// recoverScheduledJobsOnStartUp handles the once-off jobs that are due but were never sent, e.g. because their river
+// job was discarded while the bot was unavailable, according to their misfire policy.
+func (c *Client) recoverScheduledJobsOnStartUp() {
+	jobs, err := c.queries.GetUnfiredScheduledJobs(context.Background())
+	if err != nil {
+		log.Err(err).Msg("Unable to get unfired scheduled jobs.")
+		return
+	}
+
+	for _, job := range jobs {
+		if err := c.recoverScheduledJob(job); err != nil {
+			log.Err(err).Msgf("Unable to recover scheduled job on service start [job: %+v].", job)
 		}
 	}
 
-	log.Info().Msgf("Added %v periodic job(s) on service start up.", len(jobs))
+	log.Info().Msgf("Checked %v scheduled job(s) on service start up.", len(jobs))
+}
+
+func (c *Client) recoverScheduledJob(job sqlc.GetUnfiredScheduledJobsRow) error {
+	ctx := context.Background()
+	fireAt, err := time.Parse(time.DateTime, job.Schedule)
+	if err != nil {
+		return fmt.Errorf("failed to parse once-off schedule [schedule: %s]: %w", job.Schedule, err)
+	}
+	if fireAt.After(time.Now()) || !job.RiverJobID.Valid {
+		return nil
+	}
+
+	riverJob, err := c.Client.JobGet(ctx, job.RiverJobID.Int64)
+	if err != nil && !errors.Is(err, rivertype.ErrNotFound) {
+		return fmt.Errorf("failed to get river job [riverJobID: %v]: %w", job.RiverJobID.Int64, err)
+	}
+	if err == nil {
+		switch {
+		// a pending river job still runs, and applies the misfire policy itself
+		case slices.Contains(pendingJobStates, riverJob.State):
+			return nil
+		// sent or cancelled before last fired times were tracked, but never cleaned up
+		case riverJob.State == rivertype.JobStateCompleted || riverJob.State == rivertype.JobStateCancelled:
+			if _, err := c.queries.DeleteJobByID(ctx, job.ID); err != nil {
+				return fmt.Errorf("failed to delete job [jobID: %v]: %w", job.ID, err)
+			}
+			return nil
+		}
+	}
+
+	if job.MisfirePolicy == MisfirePolicySkip {
+		if err := notifySkippedMisfire(ctx, c.botClient, c.queries, job.TelegramChatID, job.Name, fireAt); err != nil {
+			return err
+		}
+		if _, err := c.queries.DeleteJobByID(ctx, job.ID); err != nil {
+			return fmt.Errorf("failed to delete job [jobID: %v]: %w", job.ID, err)
+		}
+		return nil
+	}
+
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	resendAt := time.Now()
+	riverJobID, err := c.AddScheduledJobTx(tx, job.ID, job.Message, bot.Media{Type: job.MediaType,
+		FileID: job.MediaFileID}, job.TelegramChatID, resendAt)
+	if err != nil {
+		return err
+	}
+	if _, err := c.queries.WithTx(tx).UpdateRiverJobID(ctx, sqlc.UpdateRiverJobIDParams{
+		RiverJobID: pgtype.Int8{Valid: true, Int64: *riverJobID},
+		NextFireAt: pgtype.Timestamp{Valid: true, Time: resendAt.UTC()},
+		ID:         job.ID,
+	}); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
 func (c *Client) processJobCompletedEvent(subscribeChan <-chan *river.Event) {
@@ -125,10 +562,11 @@ func (c *Client) processJobCompletedEvent(subscribeChan <-chan *river.Event) {
 			return
 		}
 
-		log.Info().Msgf("Received river job completed event [riverJobID: %v][Kind: %v]", event.Job.ID,
-			event.Job.Kind)
+		log.Info().Msgf("Received river job completed event [riverJobID: %v][Kind: %v][State: %v]", event.Job.ID,
+			event.Job.Kind, event.Job.State)
 
-		if event.Job.Kind == "scheduled" {
+		// one-off jobs that were cancelled or ran out of attempts are finished too
+		if event.Job.Kind == "scheduled" && event.Job.FinalizedAt != nil {
 			if _, err := c.queries.DeleteScheduledJobByRiverJobID(context.Background(), pgtype.Int8{Valid: true,
 				Int64: event.Job.ID}); err != nil {
 				log.Err(err).Msgf("Unable to delete scheduled job [riverJobID: %v].", event.Job.ID)
@@ -137,24 +575,32 @@ func (c *Client) processJobCompletedEvent(subscribeChan <-chan *river.Event) {
 	}
 }
 
-func setupRiverClient(envCfg config.EnvConfig, pool *pgxpool.Pool, botClient *bot.Client) (*river.Client[pgx.Tx], <-chan *river.Event, func()) {
+func setupRiverClient(envCfg config.EnvConfig, pool *pgxpool.Pool, botClient *bot.Client, queries *sqlc.Queries) (*river.Client[pgx.Tx], <-chan *river.Event, func()) {
+	queues, err := newQueues(envCfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Unable to configure River queues.")
+	}
+
 	workers := river.NewWorkers()
-	river.AddWorker(workers, NewScheduledJobWorker(botClient))
-	river.AddWorker(workers, NewPeriodicJobWorker(botClient))
+	river.AddWorker(workers, NewScheduledJobWorker(botClient, queries, queues[ScheduledJobArgs{}.Kind()].Timeout))
+	river.AddWorker(workers, NewPeriodicJobWorker(botClient, queries, pool, queues[PeriodicJobArgs{}.Kind()].Timeout))
+	river.AddWorker(workers, NewSnoozeJobWorker(botClient, queries, queues[SnoozeJobArgs{}.Kind()].Timeout))
+	river.AddWorker(workers, NewNagJobWorker(botClient, queries, queues[NagJobArgs{}.Kind()].Timeout))
 
 	riverClient, err := river.NewClient(riverpgxv5.New(pool), &river.Config{
-		Logger: slog.Default(),
-		Queues: map[string]river.QueueConfig{
-			river.QueueDefault: {MaxWorkers: 100},
-		},
-		TestOnly: envCfg.IsDev(),
-		Workers:  workers,
+		JobInsertMiddleware: []rivertype.JobInsertMiddleware{&queueMiddleware{queues: queues}},
+		JobTimeout:          maxQueueTimeout(queues),
+		Logger:              slog.Default(),
+		Queues:              riverQueues(queues),
+		TestOnly:            envCfg.IsDev(),
+		Workers:             workers,
 	})
 	if err != nil {
 		log.Fatal().Err(err).Msg("Unable to initialize new River client.")
 	}
 
-	completedEventChannel, cancelCompletedEventChannel := riverClient.Subscribe(river.EventKindJobCompleted)
+	completedEventChannel, cancelCompletedEventChannel := riverClient.Subscribe(river.EventKindJobCompleted,
+		river.EventKindJobCancelled, river.EventKindJobFailed)
 
 	if err := riverClient.Start(context.Background()); err != nil {
 		log.Fatal().Err(err).Msg("Unable to start River client.")
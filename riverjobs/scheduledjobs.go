@@ -2,16 +2,23 @@ package riverjobs
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/riverqueue/river"
+	"github.com/rs/zerolog/log"
 
 	"remembertelebot/bot"
+	"remembertelebot/db/sqlc"
 )
 
 type ScheduledJobArgs struct {
-	Message string `json:"message"`
-	ChatID  int64  `json:"chat_id"`
+	JobID   int32     `json:"job_id"`
+	Message string    `json:"message"`
+	Media   bot.Media `json:"media,omitzero"`
+	ChatID  int64     `json:"chat_id"`
 }
 
 func (ScheduledJobArgs) Kind() string { return "scheduled" }
@@ -19,16 +26,41 @@ func (ScheduledJobArgs) Kind() string { return "scheduled" }
 type ScheduledJobWorker struct {
 	river.WorkerDefaults[ScheduledJobArgs]
 	botClient *bot.Client
+	queries   *sqlc.Queries
+	timeout   time.Duration
 }
 
-func NewScheduledJobWorker(botClient *bot.Client) *ScheduledJobWorker {
+func NewScheduledJobWorker(botClient *bot.Client, queries *sqlc.Queries, timeout time.Duration) *ScheduledJobWorker {
 	return &ScheduledJobWorker{
 		botClient: botClient,
+		queries:   queries,
+		timeout:   timeout,
 	}
 }
 
+func (w *ScheduledJobWorker) Timeout(*river.Job[ScheduledJobArgs]) time.Duration {
+	return w.timeout
+}
+
 func (w *ScheduledJobWorker) Work(ctx context.Context, job *river.Job[ScheduledJobArgs]) error {
-	if err := w.botClient.SendPlainMessage(job.Args.ChatID, job.Args.Message); err != nil {
+	// a reminder that was due while the bot was unavailable is only sent late if its job allows it
+	if isMisfire(job.JobRow) {
+		scheduledJob, err := w.queries.GetJobByID(ctx, job.Args.JobID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get job [jobID: %v]: %w", job.Args.JobID, err)
+		}
+		if err == nil && scheduledJob.MisfirePolicy == MisfirePolicySkip {
+			log.Info().Msgf("Skipping scheduled job missed while unavailable [jobArgs: %+v].", job.Args)
+			if err := notifySkippedMisfire(ctx, w.botClient, w.queries, job.Args.ChatID, scheduledJob.Name,
+				job.ScheduledAt); err != nil {
+				log.Err(err).Msgf("Unable to notify chat of skipped scheduled job [jobID: %v].", job.Args.JobID)
+			}
+			return nil
+		}
+	}
+
+	if err := deliverReminder(ctx, w.botClient, w.queries, job.JobRow, job.Args.JobID, job.Args.ChatID,
+		job.Args.Message, job.Args.Media, 0); err != nil {
 		return fmt.Errorf("failed to send scheduled message [jobArgs: %+v]: %w", job.Args, err)
 	}
 	return nil
@@ -0,0 +1,264 @@
+package riverjobs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jsuar/go-cron-descriptor/pkg/crondescriptor"
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	day         = 24 * time.Hour
+	minInterval = time.Minute
+)
+
+func LoadLocation(timeZone string) *time.Location {
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Unable to load time zone, falling back to UTC [timeZone: %s].", timeZone)
+		return time.UTC
+	}
+	return loc
+}
+
+// ParseCronTab parses a standard cron tab, or an extended one using L, W or # (see ExtendedSchedule), in loc.
+func ParseCronTab(cronTab string, loc *time.Location) (cron.Schedule, error) {
+	if IsExtendedCronTab(cronTab) {
+		schedule, err := ParseExtendedCronTab(cronTab, loc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse extended cron tab [cronTab: %s]: %w", cronTab, err)
+		}
+		return schedule, nil
+	}
+
+	schedule, err := cron.ParseStandard(cronTab)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cron tab [cronTab: %s]: %w", cronTab, err)
+	}
+
+	if specSchedule, ok := schedule.(*cron.SpecSchedule); ok {
+		specSchedule.Location = loc
+	}
+	return schedule, nil
+}
+
+func FormatLocalTime(t time.Time, loc *time.Location) string {
+	return fmt.Sprintf("%s (%s)", t.In(loc).Format(time.DateTime), loc.String())
+}
+
+func FormatJobEnd(endDate string, maxOccurrences string) string {
+	switch {
+	case endDate != "":
+		return fmt.Sprintf("Until %s", endDate)
+	case maxOccurrences != "":
+		return fmt.Sprintf("After %s time(s)", maxOccurrences)
+	}
+	return "Never (until cancelled)"
+}
+
+func FormatLocalTimestamp(schedule string, loc *time.Location) string {
+	timestamp, err := time.Parse(time.DateTime, schedule)
+	if err != nil {
+		return schedule
+	}
+	return FormatLocalTime(timestamp, loc)
+}
+
+func DescribeRecurrence(recurrence Recurrence, loc *time.Location) string {
+	if recurrence.IsInterval() {
+		return fmt.Sprintf("Recurring %s", recurrence.Describe(loc))
+	}
+	return fmt.Sprintf("Recurring at %s (%s) in %s", recurrence.CronTab, GetCronDescriptor(recurrence.CronTab),
+		loc.String())
+}
+
+func GetCronDescriptor(cronTab string) string {
+	// crondescriptor reads L-n as a range, so describe it as the last day and then say how many days before.
+	fields := strings.Fields(cronTab)
+	if len(fields) == 5 {
+		if offset, ok := strings.CutPrefix(fields[2], "L-"); ok {
+			fields[2] = "L"
+			return strings.Replace(GetCronDescriptor(strings.Join(fields, " ")), "on the last day of the month",
+				fmt.Sprintf("%s day(s) before the last day of the month", offset), 1)
+		}
+	}
+
+	cd, _ := crondescriptor.NewCronDescriptor(cronTab)
+	if cd != nil {
+		description, _ := cd.GetDescription(crondescriptor.Full)
+		return *description
+	}
+	return ""
+}
+
+// EndOfDate returns the instant a recurring job that runs until the given local date (inclusive) ends.
+func EndOfDate(date string, loc *time.Location) (time.Time, error) {
+	start, err := time.ParseInLocation(time.DateOnly, date, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return start.AddDate(0, 0, 1).UTC(), nil
+}
+
+// FormatEndDate is the inverse of EndOfDate, i.e. the last local date on which a recurring job still runs.
+func FormatEndDate(endsAt time.Time, loc *time.Location) string {
+	return endsAt.In(loc).AddDate(0, 0, -1).Format(time.DateOnly)
+}
+
+// Recurrence describes when a recurring job fires: either a cron tab, or a fixed interval counted from an anchor.
+type Recurrence struct {
+	CronTab  string
+	Interval time.Duration
+	AnchorAt time.Time
+}
+
+func NewRecurrence(schedule string, intervalSeconds pgtype.Int4, anchorAt pgtype.Timestamp) Recurrence {
+	if intervalSeconds.Valid && anchorAt.Valid {
+		return Recurrence{
+			CronTab:  schedule,
+			Interval: time.Duration(intervalSeconds.Int32) * time.Second,
+			AnchorAt: anchorAt.Time,
+		}
+	}
+	return Recurrence{CronTab: schedule}
+}
+
+func (r Recurrence) IsInterval() bool {
+	return r.Interval > 0
+}
+
+func (r Recurrence) Schedule(loc *time.Location) (cron.Schedule, error) {
+	if r.IsInterval() {
+		return IntervalSchedule{Interval: r.Interval, AnchorAt: r.AnchorAt, Location: loc}, nil
+	}
+	return ParseCronTab(r.CronTab, loc)
+}
+
+func (r Recurrence) IntervalSeconds() pgtype.Int4 {
+	return pgtype.Int4{Valid: r.IsInterval(), Int32: int32(r.Interval / time.Second)}
+}
+
+func (r Recurrence) AnchorTimestamp() pgtype.Timestamp {
+	return pgtype.Timestamp{Valid: r.IsInterval(), Time: r.AnchorAt.UTC()}
+}
+
+func (r Recurrence) Describe(loc *time.Location) string {
+	return fmt.Sprintf("every %s starting %s", FormatInterval(r.Interval), FormatLocalTime(r.AnchorAt, loc))
+}
+
+// IntervalSchedule fires at AnchorAt and every Interval after it. Occurrences are counted from the anchor rather
+// than from the previous run, so they never drift however late a run is. Whole-day intervals are stepped in
+// calendar days of Location, so they keep their wall clock time across daylight saving changes.
+type IntervalSchedule struct {
+	Interval time.Duration
+	AnchorAt time.Time
+	Location *time.Location
+}
+
+func (s IntervalSchedule) Next(t time.Time) time.Time {
+	if t.Before(s.AnchorAt) {
+		return s.AnchorAt
+	}
+
+	n := int(t.Sub(s.AnchorAt) / s.Interval)
+	if s.Interval%day != 0 {
+		return s.AnchorAt.Add(time.Duration(n+1) * s.Interval)
+	}
+
+	days := int(s.Interval / day)
+	anchor := s.AnchorAt.In(s.Location)
+	next := anchor.AddDate(0, 0, n*days)
+	for !next.After(t) {
+		n++
+		next = anchor.AddDate(0, 0, n*days)
+	}
+	return next
+}
+
+// ParseInterval parses "every <interval> [from <anchor>]", where interval is e.g. 90m, 1h30m or 3d, and anchor is
+// now, HH:MM or YYYY-MM-DD HH:MM in loc. Without an anchor the interval counts from now.
+func ParseInterval(text string, loc *time.Location) (Recurrence, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	spec, ok := strings.CutPrefix(text, "every ")
+	if !ok {
+		return Recurrence{}, errors.New("interval must start with every")
+	}
+	intervalText, anchorText, _ := strings.Cut(spec, " from ")
+
+	interval, err := parseIntervalDuration(strings.TrimSpace(intervalText))
+	if err != nil {
+		return Recurrence{}, err
+	}
+	if interval < minInterval || interval > 365*day {
+		return Recurrence{}, errors.New("interval must be between 1 minute and 365 days")
+	}
+
+	now := time.Now().In(loc).Truncate(time.Minute)
+	anchorAt := now
+	switch anchorText = strings.TrimSpace(anchorText); anchorText {
+	case "", "now":
+	default:
+		if clock, err := time.ParseInLocation("15:04", anchorText, loc); err == nil {
+			anchorAt = time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+		} else if anchorAt, err = time.ParseInLocation("2006-01-02 15:04", anchorText, loc); err != nil {
+			return Recurrence{}, errors.New("start must be now, HH:MM or YYYY-MM-DD HH:MM")
+		}
+	}
+
+	return Recurrence{
+		CronTab:  fmt.Sprintf("@every %s", interval.String()),
+		Interval: interval,
+		AnchorAt: anchorAt.UTC(),
+	}, nil
+}
+
+func parseIntervalDuration(text string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(text, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse interval [interval: %s]: %w", text, err)
+		}
+		return time.Duration(n) * day, nil
+	}
+
+	interval, err := time.ParseDuration(text)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse interval [interval: %s]: %w", text, err)
+	}
+	return interval.Truncate(time.Minute), nil
+}
+
+func FormatInterval(interval time.Duration) string {
+	if interval%day == 0 {
+		return fmt.Sprintf("%d day(s)", interval/day)
+	}
+	text := strings.TrimSuffix(interval.String(), "0s")
+	if interval%time.Hour == 0 {
+		text = strings.TrimSuffix(text, "0m")
+	}
+	return text
+}
+
+// ParseRecurrence rebuilds a recurrence from its string form, as kept in a chat context while a job is being created.
+func ParseRecurrence(schedule string, intervalSeconds string, anchorAt string) (Recurrence, error) {
+	if intervalSeconds == "" {
+		return Recurrence{CronTab: schedule}, nil
+	}
+
+	seconds, err := strconv.Atoi(intervalSeconds)
+	if err != nil {
+		return Recurrence{}, fmt.Errorf("failed to parse interval seconds [intervalSeconds: %s]: %w", intervalSeconds,
+			err)
+	}
+	anchor, err := time.Parse(time.DateTime, anchorAt)
+	if err != nil {
+		return Recurrence{}, fmt.Errorf("failed to parse anchor [anchorAt: %s]: %w", anchorAt, err)
+	}
+	return Recurrence{CronTab: schedule, Interval: time.Duration(seconds) * time.Second, AnchorAt: anchor}, nil
+}
@@ -0,0 +1,178 @@
+package riverjobs
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestIntervalScheduleNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation returned error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		schedule IntervalSchedule
+		after    time.Time
+		want     time.Time
+	}{
+		{
+			name: "before the anchor",
+			schedule: IntervalSchedule{Interval: 90 * time.Minute, AnchorAt: time.Date(2025, time.January, 1, 8, 15,
+				0, 0, time.UTC), Location: time.UTC},
+			after: time.Date(2025, time.January, 1, 8, 0, 0, 0, time.UTC),
+			want:  time.Date(2025, time.January, 1, 8, 15, 0, 0, time.UTC),
+		},
+		{
+			name: "at the anchor",
+			schedule: IntervalSchedule{Interval: 90 * time.Minute, AnchorAt: time.Date(2025, time.January, 1, 8, 15,
+				0, 0, time.UTC), Location: time.UTC},
+			after: time.Date(2025, time.January, 1, 8, 15, 0, 0, time.UTC),
+			want:  time.Date(2025, time.January, 1, 9, 45, 0, 0, time.UTC),
+		},
+		{
+			name: "between occurrences",
+			schedule: IntervalSchedule{Interval: 90 * time.Minute, AnchorAt: time.Date(2025, time.January, 1, 8, 15,
+				0, 0, time.UTC), Location: time.UTC},
+			after: time.Date(2025, time.January, 2, 10, 0, 0, 0, time.UTC),
+			want:  time.Date(2025, time.January, 2, 11, 15, 0, 0, time.UTC),
+		},
+		{
+			name: "whole days",
+			schedule: IntervalSchedule{Interval: 3 * day, AnchorAt: time.Date(2025, time.January, 1, 9, 0, 0, 0,
+				time.UTC), Location: time.UTC},
+			after: time.Date(2025, time.January, 4, 9, 0, 0, 0, time.UTC),
+			want:  time.Date(2025, time.January, 7, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "whole days keep their wall clock time across daylight saving",
+			schedule: IntervalSchedule{Interval: day, AnchorAt: time.Date(2025, time.March, 8, 9, 0, 0, 0, newYork),
+				Location: newYork},
+			after: time.Date(2025, time.March, 9, 8, 30, 0, 0, newYork),
+			want:  time.Date(2025, time.March, 9, 9, 0, 0, 0, newYork),
+		},
+		{
+			name: "hours do not follow daylight saving",
+			schedule: IntervalSchedule{Interval: 24*time.Hour + time.Hour, AnchorAt: time.Date(2025, time.March, 8,
+				9, 0, 0, 0, newYork), Location: newYork},
+			after: time.Date(2025, time.March, 8, 9, 0, 0, 0, newYork),
+			want:  time.Date(2025, time.March, 9, 11, 0, 0, 0, newYork),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	now := time.Now().In(loc)
+
+	tests := []struct {
+		text         string
+		wantInterval time.Duration
+		wantAnchorAt time.Time
+	}{
+		{
+			text:         "every 90m from 08:15",
+			wantInterval: 90 * time.Minute,
+			wantAnchorAt: time.Date(now.Year(), now.Month(), now.Day(), 8, 15, 0, 0, loc),
+		},
+		{
+			text:         " Every 1h30m from 2025-01-31 09:00 ",
+			wantInterval: 90 * time.Minute,
+			wantAnchorAt: time.Date(2025, time.January, 31, 9, 0, 0, 0, loc),
+		},
+		{
+			text:         "every 3d from 2025-01-31 09:00",
+			wantInterval: 3 * day,
+			wantAnchorAt: time.Date(2025, time.January, 31, 9, 0, 0, 0, loc),
+		},
+		{
+			// intervals are whole minutes
+			text:         "every 2m30s from 2025-01-31 09:00",
+			wantInterval: 2 * time.Minute,
+			wantAnchorAt: time.Date(2025, time.January, 31, 9, 0, 0, 0, loc),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			recurrence, err := ParseInterval(tt.text, loc)
+			if err != nil {
+				t.Fatalf("ParseInterval(%q) returned error: %v", tt.text, err)
+			}
+			if recurrence.Interval != tt.wantInterval {
+				t.Errorf("ParseInterval(%q) interval = %v, want %v", tt.text, recurrence.Interval, tt.wantInterval)
+			}
+			if !recurrence.AnchorAt.Equal(tt.wantAnchorAt) {
+				t.Errorf("ParseInterval(%q) anchor = %v, want %v", tt.text, recurrence.AnchorAt, tt.wantAnchorAt)
+			}
+			if recurrence.AnchorAt.Location() != time.UTC {
+				t.Errorf("ParseInterval(%q) anchor is in %v, want UTC", tt.text, recurrence.AnchorAt.Location())
+			}
+		})
+	}
+}
+
+func TestParseIntervalFromNow(t *testing.T) {
+	for _, text := range []string{"every 2h", "every 2h from now"} {
+		t.Run(text, func(t *testing.T) {
+			before := time.Now().Truncate(time.Minute)
+			recurrence, err := ParseInterval(text, time.UTC)
+			if err != nil {
+				t.Fatalf("ParseInterval(%q) returned error: %v", text, err)
+			}
+			if recurrence.AnchorAt.Before(before) || recurrence.AnchorAt.After(time.Now()) {
+				t.Errorf("ParseInterval(%q) anchor = %v, want now", text, recurrence.AnchorAt)
+			}
+		})
+	}
+}
+
+func TestParseIntervalErrors(t *testing.T) {
+	for _, text := range []string{
+		"90m",
+		"every",
+		"every soon",
+		"every 30s",
+		"every 366d",
+		"every xd",
+		"every 2h from today",
+		"every 2h from tomorrow",
+		"every 2h from 25:00",
+	} {
+		t.Run(text, func(t *testing.T) {
+			if _, err := ParseInterval(text, time.UTC); err == nil {
+				t.Errorf("ParseInterval(%q) returned no error", text)
+			}
+		})
+	}
+}
+
+func TestFormatInterval(t *testing.T) {
+	tests := []struct {
+		interval time.Duration
+		want     string
+	}{
+		{interval: 90 * time.Minute, want: "1h30m"},
+		{interval: 2 * time.Hour, want: "2h"},
+		{interval: 45 * time.Minute, want: "45m"},
+		{interval: 3 * day, want: "3 day(s)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := FormatInterval(tt.interval); got != tt.want {
+				t.Errorf("FormatInterval(%v) = %q, want %q", tt.interval, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package riverjobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/riverqueue/river"
+
+	"remembertelebot/bot"
+	"remembertelebot/db/sqlc"
+)
+
+type SnoozeJobArgs struct {
+	DeliveryID int32     `json:"delivery_id"`
+	Message    string    `json:"message"`
+	Media      bot.Media `json:"media,omitzero"`
+	ChatID     int64     `json:"chat_id"`
+}
+
+func (SnoozeJobArgs) Kind() string { return "snooze" }
+
+type SnoozeJobWorker struct {
+	river.WorkerDefaults[SnoozeJobArgs]
+	botClient *bot.Client
+	queries   *sqlc.Queries
+	timeout   time.Duration
+}
+
+func NewSnoozeJobWorker(botClient *bot.Client, queries *sqlc.Queries, timeout time.Duration) *SnoozeJobWorker {
+	return &SnoozeJobWorker{
+		botClient: botClient,
+		queries:   queries,
+		timeout:   timeout,
+	}
+}
+
+func (w *SnoozeJobWorker) Timeout(*river.Job[SnoozeJobArgs]) time.Duration {
+	return w.timeout
+}
+
+func (w *SnoozeJobWorker) Work(ctx context.Context, job *river.Job[SnoozeJobArgs]) error {
+	var err error
+	if job.Args.DeliveryID == 0 {
+		err = deliverReminder(ctx, w.botClient, w.queries, job.JobRow, 0, job.Args.ChatID, job.Args.Message,
+			job.Args.Media, 0)
+	} else {
+		err = w.deliverSnoozedReminder(ctx, job)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to send snoozed message [jobArgs: %+v]: %w", job.Args, err)
+	}
+	return nil
+}
+
+// deliverSnoozedReminder sends a snoozed reminder as a delivery of its own, linked to the delivery that was snoozed,
+// so that the original keeps its message and a retried river job does not send the reminder twice.
+func (w *SnoozeJobWorker) deliverSnoozedReminder(ctx context.Context, job *river.Job[SnoozeJobArgs]) error {
+	delivery, err := w.queries.CreateSnoozeDelivery(ctx, sqlc.CreateSnoozeDeliveryParams{
+		RiverJobID: job.ID,
+		FireAt:     pgtype.Timestamp{Valid: true, Time: job.ScheduledAt.UTC()},
+		ID:         job.Args.DeliveryID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create snooze delivery [deliveryID: %v][riverJobID: %v]: %w",
+			job.Args.DeliveryID, job.ID, err)
+	}
+
+	if delivery.SentAt.Valid {
+		return nil
+	}
+	return sendReminder(ctx, w.botClient, w.queries, job.JobRow, delivery.ID, job.Args.ChatID, job.Args.Message,
+		job.Args.Media, 0)
+}
@@ -2,10 +2,12 @@ package callbackqueries
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -21,6 +23,7 @@ import (
 const (
 	ScheduledQueryData  = "scheduled"
 	PeriodicQueryData   = "periodic"
+	IntervalQueryData   = "interval"
 	ConfirmJobQueryData = "confirm-job"
 )
 
@@ -44,20 +47,42 @@ func (h *Handler) ProcessCallbackQuery(query *tgbotapi.CallbackQuery) {
 	log.Info().Msgf("Received callback query from %s: [queryData: %s][chatID: %v]", query.From.UserName,
 		query.Data, query.Message.Chat.ID)
 
-	switch query.Data {
-	case ScheduledQueryData:
+	switch {
+	case query.Data == ScheduledQueryData:
 		h.processScheduled(query)
-	case PeriodicQueryData:
+	case query.Data == PeriodicQueryData:
 		h.processPeriodic(query)
-	case ConfirmJobQueryData:
+	case query.Data == IntervalQueryData:
+		h.processInterval(query)
+	case query.Data == ConfirmJobQueryData:
 		h.processConfirmJob(query)
+	case strings.HasPrefix(query.Data, PauseJobQueryDataPrefix),
+		strings.HasPrefix(query.Data, ResumeJobQueryDataPrefix):
+		h.processPauseJob(query)
+	case strings.HasPrefix(query.Data, EditJobQueryDataPrefix):
+		h.processEditJob(query)
+	case strings.HasPrefix(query.Data, CancelJobsQueryDataPrefix):
+		h.processCancelJobs(query)
+	case strings.HasPrefix(query.Data, JobsQueryDataPrefix):
+		h.processJobs(query)
+	case strings.HasPrefix(query.Data, HistoryQueryDataPrefix):
+		h.processHistory(query)
+	case strings.HasPrefix(query.Data, riverjobs.SkipNextQueryData):
+		h.processSkipNext(query)
+	case strings.HasPrefix(query.Data, riverjobs.DoneQueryData):
+		h.processDone(query)
+	case strings.HasPrefix(query.Data, riverjobs.SnoozeCustomQueryData):
+		h.processCustomSnooze(query)
+	case strings.HasPrefix(query.Data, riverjobs.SnoozeQueryDataPrefix):
+		h.processSnooze(query)
 	default:
 		h.processDefault(query)
 	}
 }
 
 func (h *Handler) processDefault(query *tgbotapi.CallbackQuery) {
-	if err := h.botClient.SendPlainMessage(query.Message.Chat.ID, "Received unknown query data."); err != nil {
+	if err := h.botClient.SendPlainMessage(context.Background(), query.Message.Chat.ID,
+		"Received unknown query data."); err != nil {
 		log.Err(err).Msgf("Unable to respond to unknown query data [user: %s].", query.From.UserName)
 		return
 	}
@@ -98,10 +123,71 @@ func (h *Handler) processConfirmJob(query *tgbotapi.CallbackQuery) {
 		return
 	}
 
+	var endsAt pgtype.Timestamp
+	if endDate := chatContextMap["end_date"]; endDate != "" && isRecurring {
+		end, err := riverjobs.EndOfDate(endDate, riverjobs.LoadLocation(chat.TimeZone))
+		if err != nil {
+			log.Err(err).Msgf("Unable to parse end date [endDate: %v][chat: %+v].", endDate, chat)
+			h.sendErrorMessage(err, query)
+			return
+		}
+		endsAt = pgtype.Timestamp{Valid: true, Time: end}
+	}
+
+	var maxOccurrences pgtype.Int4
+	if times := chatContextMap["max_occurrences"]; times != "" && isRecurring {
+		count, err := strconv.Atoi(times)
+		if err != nil {
+			log.Err(err).Msgf("Unable to parse max occurrences [maxOccurrences: %v][chat: %+v].", times, chat)
+			h.sendErrorMessage(err, query)
+			return
+		}
+		maxOccurrences = pgtype.Int4{Valid: true, Int32: int32(count)}
+	}
+
+	recurrence := riverjobs.Recurrence{CronTab: chatContextMap["schedule"]}
+	if isRecurring {
+		recurrence, err = riverjobs.ParseRecurrence(chatContextMap["schedule"], chatContextMap["interval_seconds"],
+			chatContextMap["anchor_at"])
+		if err != nil {
+			log.Err(err).Msgf("Unable to parse recurrence [chat: %+v].", chat)
+			h.sendErrorMessage(err, query)
+			return
+		}
+	}
+
+	tags := []string{}
+	if chatContextMap["tags"] != "" {
+		tags = strings.Split(chatContextMap["tags"], ",")
+	}
+
+	media := bot.Media{Type: chatContextMap["media_type"], FileID: chatContextMap["media_file_id"]}
+	qtx := h.queries.WithTx(tx)
+	job, err := qtx.CreateJob(ctx, sqlc.CreateJobParams{
+		TelegramChatID:  query.Message.Chat.ID,
+		IsRecurring:     isRecurring,
+		Message:         chatContextMap["message"],
+		Schedule:        chatContextMap["schedule"],
+		Name:            chatContextMap["name"],
+		EndsAt:          endsAt,
+		MaxOccurrences:  maxOccurrences,
+		IntervalSeconds: recurrence.IntervalSeconds(),
+		AnchorAt:        recurrence.AnchorTimestamp(),
+		Tags:            tags,
+		MediaType:       media.Type,
+		MediaFileID:     media.FileID,
+	})
+	if err != nil {
+		log.Err(err).Msgf("Unable to add new job to db [chat: %+v].", chat)
+		h.sendErrorMessage(err, query)
+		return
+	}
+
 	var riverJobID *int64
+	var fireAt time.Time
 	if isRecurring {
-		riverJobID, err = h.riverClient.AddPeriodicJob(chatContextMap["message"], chat.TelegramChatID,
-			chatContextMap["schedule"])
+		riverJobID, fireAt, err = h.riverClient.AddPeriodicJobTx(tx, job.ID, chat.TelegramChatID, recurrence,
+			chat.TimeZone)
 		if err != nil {
 			log.Err(err).Msgf("Unable to add periodic job to river client [chat: %+v].",
 				chat)
@@ -110,14 +196,15 @@ func (h *Handler) processConfirmJob(query *tgbotapi.CallbackQuery) {
 		}
 
 	} else {
-		schedule, err := time.Parse(time.DateTime, chatContextMap["schedule"])
+		fireAt, err = time.Parse(time.DateTime, chatContextMap["schedule"])
 		if err != nil {
 			log.Err(err).Msgf("Unable to parse once-off schedule to time [schedule: %v][chat: %+v].",
 				chatContextMap["schedule"], chat)
 			h.sendErrorMessage(err, query)
 			return
 		}
-		riverJobID, err = h.riverClient.AddScheduledJobTx(tx, chatContextMap["message"], chat.TelegramChatID, schedule)
+		riverJobID, err = h.riverClient.AddScheduledJobTx(tx, job.ID, chatContextMap["message"], media,
+			chat.TelegramChatID, fireAt)
 		if err != nil {
 			log.Err(err).Msgf("Unable to add scheduled job to river client [chat: %+v].",
 				chat)
@@ -134,17 +221,13 @@ func (h *Handler) processConfirmJob(query *tgbotapi.CallbackQuery) {
 		return
 	}
 
-	qtx := h.queries.WithTx(tx)
-	if _, err := qtx.CreateJob(ctx, sqlc.CreateJobParams{
-		TelegramChatID: query.Message.Chat.ID,
-		IsRecurring:    isRecurring,
-		Message:        chatContextMap["message"],
-		Schedule:       chatContextMap["schedule"],
-		Name:           chatContextMap["name"],
-		RiverJobID:     pgtype.Int8{Valid: true, Int64: *riverJobID},
+	if _, err := qtx.UpdateRiverJobID(ctx, sqlc.UpdateRiverJobIDParams{
+		RiverJobID: pgtype.Int8{Valid: true, Int64: *riverJobID},
+		NextFireAt: pgtype.Timestamp{Valid: true, Time: fireAt.UTC()},
+		ID:         job.ID,
 	}); err != nil {
-		log.Err(err).Msgf("Unable to add new job to db [chat: %+v][riverJobID: %v].",
-			chat, *riverJobID)
+		log.Err(err).Msgf("Unable to update river job ID [jobID: %v][riverJobID: %v].",
+			job.ID, *riverJobID)
 		h.sendErrorMessage(err, query)
 		return
 	}
@@ -161,14 +244,57 @@ func (h *Handler) processConfirmJob(query *tgbotapi.CallbackQuery) {
 
 	// edit the previous html message with confirmation button
 	text := fmt.Sprintf("Successfully scheduled job %s", chatContextMap["name"])
-	if err := h.botClient.SendEditMessage(query.Message.Chat.ID, query.Message.MessageID, text); err != nil {
+	if err := h.botClient.SendEditMessage(ctx, query.Message.Chat.ID, query.Message.MessageID, text); err != nil {
 		log.Err(err).Msgf("Unable to edit html markup to send success message [user: %s].",
 			query.From.UserName)
 		return
 	}
 }
 
-func (h *Handler) processJobType(query *tgbotapi.CallbackQuery, isRecurring string) {
+func NewJobTypeKeyboard() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Once-off", ScheduledQueryData),
+		),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Recurring", PeriodicQueryData)),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Every interval", IntervalQueryData)),
+	)
+}
+
+// JobType returns the query data of the button that selects the given type of job.
+func JobType(isRecurring bool, isInterval bool) string {
+	switch {
+	case isInterval:
+		return IntervalQueryData
+	case isRecurring:
+		return PeriodicQueryData
+	default:
+		return ScheduledQueryData
+	}
+}
+
+// SchedulePrompt asks for the schedule of a new job of the given type, in the chat's time zone.
+func SchedulePrompt(jobType string, timeZone string) string {
+	switch jobType {
+	case IntervalQueryData:
+		return fmt.Sprintf("Please input the interval and the time in %s it starts from, e.g. every 90m from 08:15, "+
+			"every 3d from 09:00 or every 2h from 2025-01-31 09:00. Without a start time, the interval starts now.",
+			timeZone)
+	case PeriodicQueryData:
+		return fmt.Sprintf("Please input the cron expression (i.e. * * * * *) in %s that the recurring message should"+
+			" be sent. L, W and # are supported too, e.g. 0 9 LW * * for the last weekday of the month or 0 9 * * 2#2 "+
+			"for the second Tuesday. \n\nAlternatively, input your schedule in natural language (e.g. Every Thursday at 5pm), "+
+			"and our friendly AI assistant will take care of you.", timeZone)
+	default:
+		return fmt.Sprintf("Please input the date and time in %s in the format YYYY-MM-DD HH:MM:SS, or in words"+
+			" (e.g. in 20 minutes, tomorrow 9am, next Tuesday 14:30 or 25 Dec 8pm), that the once-off message should be sent. \n\nFor anything else (e.g. the Friday after next at "+
+			"lunch), our friendly AI assistant will take care of you.", timeZone)
+	}
+}
+
+func (h *Handler) processJobType(query *tgbotapi.CallbackQuery, jobType string) {
+	isRecurring := strconv.FormatBool(jobType != ScheduledQueryData)
+
 	ctx := context.Background()
 
 	chat, err := h.queries.GetChat(ctx, query.Message.Chat.ID)
@@ -186,6 +312,9 @@ func (h *Handler) processJobType(query *tgbotapi.CallbackQuery, isRecurring stri
 	}
 
 	chatContextMap["is_recurring"] = isRecurring
+	if jobType == IntervalQueryData {
+		chatContextMap["is_interval"] = "true"
+	}
 	contextMapBytes, err := json.Marshal(chatContextMap)
 	if err != nil {
 		log.Err(err).Msgf("Unable to marshal chat context [contextMap: %+v].", chatContextMap)
@@ -207,14 +336,8 @@ func (h *Handler) processJobType(query *tgbotapi.CallbackQuery, isRecurring stri
 	_ = h.botClient.SendCallbackConfig(query.ID, "")
 
 	// edit the previous html message with buttons
-	text := "Please input the UTC date and time in the format YYYY-MM-DD HH:MM:SS that the once-off message should be" +
-		" sent."
-	if isRecurring == "true" {
-		text = "Please input the UTC cron expression (i.e. * * * * * *) that the recurring message should be sent. " +
-			"\n\nAlternatively, input your schedule and country of residence in natural language (e.g. " +
-			"Every Thursday at 5pm, Singapore), and our friendly AI assistant will take care of you."
-	}
-	if err := h.botClient.SendEditMessage(query.Message.Chat.ID, query.Message.MessageID, text); err != nil {
+	text := SchedulePrompt(jobType, chat.TimeZone)
+	if err := h.botClient.SendEditMessage(ctx, query.Message.Chat.ID, query.Message.MessageID, text); err != nil {
 		log.Err(err).Msgf("Unable to edit html markup to send request for schedule [user: %s].",
 			query.From.UserName)
 		return
@@ -222,15 +345,181 @@ func (h *Handler) processJobType(query *tgbotapi.CallbackQuery, isRecurring stri
 }
 
 func (h *Handler) processPeriodic(query *tgbotapi.CallbackQuery) {
-	h.processJobType(query, "true")
+	h.processJobType(query, PeriodicQueryData)
+}
+
+func (h *Handler) processInterval(query *tgbotapi.CallbackQuery) {
+	h.processJobType(query, IntervalQueryData)
 }
 
 func (h *Handler) processScheduled(query *tgbotapi.CallbackQuery) {
-	h.processJobType(query, "false")
+	h.processJobType(query, ScheduledQueryData)
+}
+
+func (h *Handler) processSnooze(query *tgbotapi.CallbackQuery) {
+	ctx := context.Background()
+	timeZone := time.UTC.String()
+	chat, err := h.queries.GetChat(ctx, query.Message.Chat.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Err(err).Msgf("Unable to get chat [telegramChatID: %v].", query.Message.Chat.ID)
+		h.sendErrorMessage(err, query)
+		return
+	}
+	if err == nil {
+		timeZone = chat.TimeZone
+	}
+	loc := riverjobs.LoadLocation(timeZone)
+
+	action, deliveryID := riverjobs.ParseReminderQueryData(query.Data)
+	now := time.Now()
+	var snoozeUntil time.Time
+	switch action {
+	case riverjobs.Snooze10MinutesQueryData:
+		snoozeUntil = now.Add(10 * time.Minute)
+	case riverjobs.Snooze1HourQueryData:
+		snoozeUntil = now.Add(time.Hour)
+	case riverjobs.SnoozeTomorrowQueryData:
+		tomorrow := now.In(loc).AddDate(0, 0, 1)
+		snoozeUntil = time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), riverjobs.SnoozeTomorrowMorningHour,
+			0, 0, 0, loc)
+	default:
+		h.processDefault(query)
+		return
+	}
+
+	message, media, err := h.getSnoozedReminder(ctx, deliveryID, query.Message)
+	if err != nil {
+		log.Err(err).Msgf("Unable to get snoozed reminder [deliveryID: %v].", deliveryID)
+		h.sendErrorMessage(err, query)
+		return
+	}
+	if _, err := h.riverClient.AddSnoozeJob(deliveryID, message, media, query.Message.Chat.ID,
+		snoozeUntil); err != nil {
+		log.Err(err).Msgf("Unable to add snooze job to river client [telegramChatID: %v].", query.Message.Chat.ID)
+		h.sendErrorMessage(err, query)
+		return
+	}
+
+	// show loader
+	_ = h.botClient.SendCallbackConfig(query.ID, "")
+
+	// edit the delivered reminder to remove the snooze buttons
+	buttonsMedia, _ := bot.MediaFromMessage(query.Message)
+	text := fmt.Sprintf("%s\n\n⏰ Snoozed until %s", reminderText(query.Message),
+		riverjobs.FormatLocalTime(snoozeUntil, loc))
+	if err := h.botClient.SendEditMediaMessage(ctx, query.Message.Chat.ID, query.Message.MessageID, buttonsMedia,
+		text); err != nil {
+		log.Err(err).Msgf("Unable to edit reminder to show snooze [user: %s].", query.From.UserName)
+		return
+	}
+}
+
+func (h *Handler) processDone(query *tgbotapi.CallbackQuery) {
+	_, deliveryID := riverjobs.ParseReminderQueryData(query.Data)
+	if _, err := h.queries.AcknowledgeDelivery(context.Background(), sqlc.AcknowledgeDeliveryParams{
+		ID:             deliveryID,
+		TelegramChatID: query.Message.Chat.ID,
+	}); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Err(err).Msgf("Unable to acknowledge delivery [deliveryID: %v].", deliveryID)
+		h.sendErrorMessage(err, query)
+		return
+	}
+
+	// show loader
+	_ = h.botClient.SendCallbackConfig(query.ID, "")
+
+	// edit the delivered reminder to remove its buttons
+	media, _ := bot.MediaFromMessage(query.Message)
+	text := fmt.Sprintf("%s\n\n✅ Done", reminderText(query.Message))
+	if err := h.botClient.SendEditMediaMessage(context.Background(), query.Message.Chat.ID, query.Message.MessageID,
+		media,
+		text); err != nil {
+		log.Err(err).Msgf("Unable to edit reminder to show acknowledgement [user: %s].", query.From.UserName)
+		return
+	}
+}
+
+func (h *Handler) processCustomSnooze(query *tgbotapi.CallbackQuery) {
+	ctx := context.Background()
+	chat, err := h.queries.GetChat(ctx, query.Message.Chat.ID)
+	if err != nil {
+		log.Err(err).Msgf("Unable to get chat [telegramChatID: %v].", query.Message.Chat.ID)
+		h.sendErrorMessage(err, query)
+		return
+	}
+
+	_, deliveryID := riverjobs.ParseReminderQueryData(query.Data)
+	message, media, err := h.getSnoozedReminder(ctx, deliveryID, query.Message)
+	if err != nil {
+		log.Err(err).Msgf("Unable to get snoozed reminder [deliveryID: %v].", deliveryID)
+		h.sendErrorMessage(err, query)
+		return
+	}
+	contextMap := map[string]string{
+		"snooze_message":     message,
+		"snooze_message_id":  strconv.Itoa(query.Message.MessageID),
+		"snooze_delivery_id": strconv.Itoa(int(deliveryID)),
+	}
+	if !media.IsZero() {
+		contextMap["snooze_media_type"] = media.Type
+		contextMap["snooze_media_file_id"] = media.FileID
+	}
+	contextMapBytes, err := json.Marshal(contextMap)
+	if err != nil {
+		log.Err(err).Msgf("Unable to marshal snooze chat context [chat: %+v].", chat)
+		h.sendErrorMessage(err, query)
+		return
+	}
+
+	if _, err := h.queries.UpdateChatContext(ctx, sqlc.UpdateChatContextParams{
+		TelegramChatID: query.Message.Chat.ID,
+		Context:        contextMapBytes,
+	}); err != nil {
+		log.Err(err).Msgf("Unable to update chat context [telegramChatID: %v].", query.Message.Chat.ID)
+		h.sendErrorMessage(err, query)
+		return
+	}
+
+	// show loader
+	_ = h.botClient.SendCallbackConfig(query.ID, "")
+
+	text := fmt.Sprintf("Please input the date and time in %s in the format YYYY-MM-DD HH:MM:SS, or in words"+
+		" (e.g. in 20 minutes, tomorrow 9am, next Tuesday 14:30 or 25 Dec 8pm), that the reminder should be snoozed until.", chat.TimeZone)
+	if err := h.botClient.SendPlainMessage(ctx, query.Message.Chat.ID, text); err != nil {
+		log.Err(err).Msgf("Unable to send request for snooze schedule [user: %s].", query.From.UserName)
+		return
+	}
+}
+
+// getSnoozedReminder returns the text and media of a delivered reminder to send again when it is snoozed. They are
+// read from its job, since a sticker reminder's buttons are on its text rather than on the sticker, and otherwise from
+// the delivered message, e.g. for a reminder that was itself snoozed without a delivery.
+func (h *Handler) getSnoozedReminder(ctx context.Context, deliveryID int32, message *tgbotapi.Message) (string,
+	bot.Media, error) {
+	reminder, err := h.queries.GetDeliveryReminder(ctx, sqlc.GetDeliveryReminderParams{
+		ID:             deliveryID,
+		TelegramChatID: message.Chat.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		media, _ := bot.MediaFromMessage(message)
+		return reminderText(message), media, nil
+	}
+	if err != nil {
+		return "", bot.Media{}, fmt.Errorf("failed to get delivery reminder [deliveryID: %v]: %w", deliveryID, err)
+	}
+	return reminder.Message, bot.Media{Type: reminder.MediaType, FileID: reminder.MediaFileID}, nil
+}
+
+// reminderText returns the text of a delivered reminder, which is the caption of a media reminder.
+func reminderText(message *tgbotapi.Message) string {
+	if message.Text != "" {
+		return message.Text
+	}
+	return message.Caption
 }
 
 func (h *Handler) sendErrorMessage(err error, query *tgbotapi.CallbackQuery) {
-	if err := h.botClient.SendPlainMessage(query.Message.Chat.ID,
+	if err := h.botClient.SendPlainMessage(context.Background(), query.Message.Chat.ID,
 		fmt.Sprintf("An error occurred processing the callback query: %v",
 			err.Error())); err != nil {
 		log.Warn().Err(err).Msgf("Unable to publish error message [user: %s][message: %v].",
@@ -0,0 +1,264 @@
+package callbackqueries
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+
+	"remembertelebot/db/sqlc"
+	"remembertelebot/riverjobs"
+)
+
+// The jobs selected for cancellation are kept in the keyboard itself: query data is
+// "cancel-jobs-select-<jobID>-<1 if selected, else 0>". Confirming /cancelall sends "cancel-jobs-all-<max job ID>",
+// the newest job the prompt counted, so jobs created after the prompt are not cancelled with it.
+const (
+	CancelJobsQueryDataPrefix        = "cancel-jobs-"
+	CancelAllJobsQueryDataPrefix     = "cancel-jobs-all-"
+	SelectCancelJobQueryDataPrefix   = "cancel-jobs-select-"
+	CancelSelectedJobsQueryData      = "cancel-jobs-selected"
+	DismissCancelJobsQueryData       = "cancel-jobs-dismiss"
+	maxSelectableJobs                = 50
+	maxSelectableJobNameLength       = 30
+	noJobsToCancelText               = "You have no jobs to cancel."
+	selectedCancelJobQueryDataSuffix = "-1"
+)
+
+func (h *Handler) processCancelJobs(query *tgbotapi.CallbackQuery) {
+	switch {
+	case strings.HasPrefix(query.Data, CancelAllJobsQueryDataPrefix):
+		h.processCancelAllJobs(query)
+	case query.Data == CancelSelectedJobsQueryData:
+		h.processCancelSelectedJobs(query)
+	case query.Data == DismissCancelJobsQueryData:
+		// show loader
+		_ = h.botClient.SendCallbackConfig(query.ID, "")
+
+		if err := h.botClient.SendEditMessage(context.Background(), query.Message.Chat.ID, query.Message.MessageID,
+			"No jobs were cancelled."); err != nil {
+			log.Err(err).Msgf("Unable to edit dismissed job cancellation [user: %s].", query.From.UserName)
+		}
+	case strings.HasPrefix(query.Data, SelectCancelJobQueryDataPrefix):
+		h.processSelectCancelJob(query)
+	default:
+		h.processDefault(query)
+	}
+}
+
+func (h *Handler) processCancelAllJobs(query *tgbotapi.CallbackQuery) {
+	var maxJobID int32
+	if _, err := fmt.Sscanf(strings.TrimPrefix(query.Data, CancelAllJobsQueryDataPrefix), "%d", &maxJobID); err != nil {
+		log.Err(err).Msgf("Invalid job ID format [queryData: %s].", query.Data)
+		h.sendErrorMessage(errors.New("invalid job ID"), query)
+		return
+	}
+
+	jobs, err := h.queries.GetActiveJobsByTelegramChatID(context.Background(), query.Message.Chat.ID)
+	if err != nil {
+		log.Err(err).Msgf("Unable to get active jobs [telegramChatID: %v].", query.Message.Chat.ID)
+		h.sendErrorMessage(err, query)
+		return
+	}
+
+	jobIDs := make([]int32, 0, len(jobs))
+	for _, job := range jobs {
+		// job IDs are serial, so a larger one was created after the prompt
+		if job.ID <= maxJobID {
+			jobIDs = append(jobIDs, job.ID)
+		}
+	}
+	h.cancelJobs(query, jobIDs)
+}
+
+func (h *Handler) processCancelSelectedJobs(query *tgbotapi.CallbackQuery) {
+	jobIDs := selectedCancelJobIDs(query.Message.ReplyMarkup)
+	if len(jobIDs) == 0 {
+		_ = h.botClient.SendCallbackConfig(query.ID, "Select the jobs to cancel first.")
+		return
+	}
+	h.cancelJobs(query, jobIDs)
+}
+
+// cancelJobs cancels the given jobs and replaces the message with what was cancelled.
+func (h *Handler) cancelJobs(query *tgbotapi.CallbackQuery, jobIDs []int32) {
+	text := noJobsToCancelText
+	if len(jobIDs) > 0 {
+		cancellations, err := h.riverClient.CancelJobs(query.Message.Chat.ID, jobIDs)
+		if err != nil {
+			log.Err(err).Msgf("Unable to cancel jobs [telegramChatID: %v][jobIDs: %v].", query.Message.Chat.ID,
+				jobIDs)
+			h.sendErrorMessage(err, query)
+			return
+		}
+		text = FormatJobCancellations(cancellations)
+	}
+
+	// show loader
+	_ = h.botClient.SendCallbackConfig(query.ID, "")
+
+	if err := h.botClient.SendEditMessage(context.Background(), query.Message.Chat.ID, query.Message.MessageID,
+		text); err != nil {
+		log.Err(err).Msgf("Unable to edit job cancellation summary [user: %s].", query.From.UserName)
+		return
+	}
+}
+
+func (h *Handler) processSelectCancelJob(query *tgbotapi.CallbackQuery) {
+	var jobID int32
+	if _, err := fmt.Sscanf(strings.TrimPrefix(query.Data, SelectCancelJobQueryDataPrefix), "%d", &jobID); err != nil {
+		log.Err(err).Msgf("Invalid job ID format [queryData: %s].", query.Data)
+		h.sendErrorMessage(errors.New("invalid job ID"), query)
+		return
+	}
+
+	selected := make(map[int32]bool)
+	for _, id := range selectedCancelJobIDs(query.Message.ReplyMarkup) {
+		selected[id] = true
+	}
+	selected[jobID] = !selected[jobID]
+
+	text, markup, err := GenerateCancelJobsSelection(h.queries, query.Message.Chat.ID, selected)
+	if err != nil {
+		log.Err(err).Msgf("Unable to generate job cancellation selection [telegramChatID: %v].",
+			query.Message.Chat.ID)
+		h.sendErrorMessage(err, query)
+		return
+	}
+
+	// show loader
+	_ = h.botClient.SendCallbackConfig(query.ID, "")
+
+	if err := h.botClient.SendEditMarkupMessage(context.Background(), query.Message.Chat.ID, query.Message.MessageID,
+		text,
+		markup); err != nil {
+		log.Err(err).Msgf("Unable to edit job cancellation selection [user: %s].", query.From.UserName)
+		return
+	}
+}
+
+// GenerateCancelAllPrompt asks to confirm cancelling every active job of the chat. Cancelled jobs are deleted, as
+// with /canceljob, rather than archived.
+func GenerateCancelAllPrompt(queries *sqlc.Queries, telegramChatID int64) (string, *tgbotapi.InlineKeyboardMarkup,
+	error) {
+	jobs, err := queries.GetActiveJobsByTelegramChatID(context.Background(), telegramChatID)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(jobs) == 0 {
+		return noJobsToCancelText, nil, nil
+	}
+
+	var maxJobID int32
+	for _, job := range jobs {
+		maxJobID = max(maxJobID, job.ID)
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("❌ Yes, cancel all %d", len(jobs)),
+			fmt.Sprintf("%s%d", CancelAllJobsQueryDataPrefix, maxJobID)),
+		tgbotapi.NewInlineKeyboardButtonData("Keep them", DismissCancelJobsQueryData),
+	))
+	return fmt.Sprintf("Cancel all %d of your jobs? This cannot be undone.", len(jobs)), &markup, nil
+}
+
+// GenerateCancelJobsSelection lists the chat's active jobs as buttons that select or deselect each job, followed by a
+// button to cancel the selected jobs.
+func GenerateCancelJobsSelection(queries *sqlc.Queries, telegramChatID int64, selected map[int32]bool) (string,
+	*tgbotapi.InlineKeyboardMarkup, error) {
+	jobs, err := queries.GetActiveJobsPageByTelegramChatID(context.Background(),
+		sqlc.GetActiveJobsPageByTelegramChatIDParams{
+			TelegramChatID: telegramChatID,
+			Limit:          maxSelectableJobs + 1,
+			Offset:         0,
+		})
+	if err != nil {
+		return "", nil, err
+	}
+	if len(jobs) == 0 {
+		return noJobsToCancelText, nil, nil
+	}
+
+	text := "Select the jobs to cancel, then tap Cancel selected."
+	if len(jobs) > maxSelectableJobs {
+		jobs = jobs[:maxSelectableJobs]
+		text += fmt.Sprintf(" Only your first %d jobs are shown; input /cancelall to cancel every job.",
+			maxSelectableJobs)
+	}
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(jobs)+1)
+	count := 0
+	for _, job := range jobs {
+		label, state := "⬜", "0"
+		if selected[job.ID] {
+			label, state = "☑️", "1"
+			count++
+		}
+
+		name := job.Name
+		if utf8.RuneCountInString(name) > maxSelectableJobNameLength {
+			name = string([]rune(name)[:maxSelectableJobNameLength]) + "…"
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%s %v: %s", label, job.ID, name),
+			fmt.Sprintf("%s%d-%s", SelectCancelJobQueryDataPrefix, job.ID, state))))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("❌ Cancel selected (%d)", count),
+			CancelSelectedJobsQueryData),
+		tgbotapi.NewInlineKeyboardButtonData("Close", DismissCancelJobsQueryData),
+	))
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return text, &markup, nil
+}
+
+func FormatJobCancellations(cancellations []riverjobs.JobCancellation) string {
+	var cancelled, failed []string
+	for _, cancellation := range cancellations {
+		if cancellation.Err != nil {
+			failed = append(failed, fmt.Sprintf("• Job ID %v: %v", cancellation.JobID, cancellation.Err))
+			continue
+		}
+		cancelled = append(cancelled, cancellation.Name)
+	}
+
+	text := "No jobs were cancelled."
+	if len(cancelled) > 0 {
+		text = fmt.Sprintf("Successfully cancelled %d job(s): %s.", len(cancelled), strings.Join(cancelled, ", "))
+	}
+	if len(failed) > 0 {
+		text += fmt.Sprintf("\n\nUnable to cancel %d job(s):\n%s", len(failed), strings.Join(failed, "\n"))
+	}
+	return text
+}
+
+// selectedCancelJobIDs reads the jobs selected for cancellation back out of the selection keyboard.
+func selectedCancelJobIDs(markup *tgbotapi.InlineKeyboardMarkup) []int32 {
+	if markup == nil {
+		return nil
+	}
+
+	var jobIDs []int32
+	for _, row := range markup.InlineKeyboard {
+		for _, button := range row {
+			if button.CallbackData == nil {
+				continue
+			}
+			data, ok := strings.CutPrefix(*button.CallbackData, SelectCancelJobQueryDataPrefix)
+			if !ok || !strings.HasSuffix(data, selectedCancelJobQueryDataSuffix) {
+				continue
+			}
+
+			var jobID int32
+			if _, err := fmt.Sscanf(data, "%d", &jobID); err == nil {
+				jobIDs = append(jobIDs, jobID)
+			}
+		}
+	}
+	return jobIDs
+}
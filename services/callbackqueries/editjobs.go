@@ -0,0 +1,109 @@
+package callbackqueries
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+
+	"remembertelebot/db/sqlc"
+)
+
+const (
+	EditJobQueryDataPrefix = "edit-job-"
+	EditJobFieldName       = "name"
+	EditJobFieldMessage    = "message"
+	EditJobFieldSchedule   = "schedule"
+)
+
+func NewEditJobKeyboard(jobID int32) tgbotapi.InlineKeyboardMarkup {
+	button := func(text string, field string) tgbotapi.InlineKeyboardButton {
+		return tgbotapi.NewInlineKeyboardButtonData(text, fmt.Sprintf("%s%s-%d", EditJobQueryDataPrefix, field, jobID))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(button("Name", EditJobFieldName)),
+		tgbotapi.NewInlineKeyboardRow(button("Message", EditJobFieldMessage)),
+		tgbotapi.NewInlineKeyboardRow(button("Schedule", EditJobFieldSchedule)),
+	)
+}
+
+func (h *Handler) processEditJob(query *tgbotapi.CallbackQuery) {
+	ctx := context.Background()
+	field, jobIDStr, _ := strings.Cut(strings.TrimPrefix(query.Data, EditJobQueryDataPrefix), "-")
+	jobID, err := strconv.Atoi(jobIDStr)
+	if err != nil {
+		log.Err(err).Msgf("Invalid job ID format [queryData: %s].", query.Data)
+		h.sendErrorMessage(errors.New("invalid job ID"), query)
+		return
+	}
+
+	job, err := GetOwnJob(h.queries, query.Message.Chat.ID, int32(jobID))
+	if err != nil {
+		log.Err(err).Msgf("Unable to get job to edit [jobID: %v].", jobID)
+		h.sendErrorMessage(err, query)
+		return
+	}
+
+	chat, err := h.queries.GetChat(ctx, query.Message.Chat.ID)
+	if err != nil {
+		log.Err(err).Msgf("Unable to get chat [telegramChatID: %v].", query.Message.Chat.ID)
+		h.sendErrorMessage(err, query)
+		return
+	}
+
+	var text string
+	switch field {
+	case EditJobFieldName:
+		text = fmt.Sprintf("Please enter the new name for job %s.", job.Name)
+	case EditJobFieldMessage:
+		text = fmt.Sprintf("Please input the new message to be scheduled for job %s.", job.Name)
+	case EditJobFieldSchedule:
+		text = fmt.Sprintf("Please input the new date and time in %s in the format YYYY-MM-DD HH:MM:SS, or in "+
+			"words (e.g. in 20 minutes, tomorrow 9am, next Tuesday 14:30 or 25 Dec 8pm), that the once-off message should be sent. \n\nFor anything else (e.g. the Friday after "+
+			"next at lunch), our friendly AI assistant will take care of you.", chat.TimeZone)
+		if job.IsRecurring {
+			text = fmt.Sprintf("Please input the new cron expression (i.e. * * * * *) in %s that the recurring "+
+				"message should be sent. L, W and # are supported too, e.g. 0 9 LW * * for the last weekday of the "+
+				"month or 0 9 * * 2#2 for the second Tuesday. \n\nAlternatively, input your schedule in natural language (e.g. Every "+
+				"Thursday at 5pm), and our friendly AI assistant will take care of you. \n\nFor a fixed interval, input "+
+				"e.g. every 90m from 08:15 or every 3d from 09:00.", chat.TimeZone)
+		}
+	default:
+		h.processDefault(query)
+		return
+	}
+
+	contextMapBytes, err := json.Marshal(map[string]string{
+		"edit_job_id": strconv.Itoa(int(job.ID)),
+		"edit_field":  field,
+	})
+	if err != nil {
+		log.Err(err).Msgf("Unable to marshal chat context [jobID: %v][field: %s].", job.ID, field)
+		h.sendErrorMessage(err, query)
+		return
+	}
+
+	if _, err := h.queries.UpdateChatContext(ctx, sqlc.UpdateChatContextParams{
+		TelegramChatID: query.Message.Chat.ID,
+		Context:        contextMapBytes,
+	}); err != nil {
+		log.Err(err).Msgf("Unable to update chat context [telegramChatID: %v].", query.Message.Chat.ID)
+		h.sendErrorMessage(err, query)
+		return
+	}
+
+	// show loader
+	_ = h.botClient.SendCallbackConfig(query.ID, "")
+
+	// edit the previous html message with buttons
+	if err := h.botClient.SendEditMessage(ctx, query.Message.Chat.ID, query.Message.MessageID, text); err != nil {
+		log.Err(err).Msgf("Unable to edit html markup to send request for job edit [user: %s].",
+			query.From.UserName)
+		return
+	}
+}
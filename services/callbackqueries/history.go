@@ -0,0 +1,146 @@
+package callbackqueries
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+
+	"remembertelebot/db/sqlc"
+	"remembertelebot/riverjobs"
+)
+
+const (
+	HistoryQueryDataPrefix = "history-"
+	DefaultHistoryPageSize = 10
+	MaxHistoryPageSize     = 50
+)
+
+// historyFailedQueryDataSuffix follows the offset and limit in the query data of the pages of /history failed.
+const historyFailedQueryDataSuffix = "-failed"
+
+func (h *Handler) processHistory(query *tgbotapi.CallbackQuery) {
+	data, isFailedOnly := strings.CutSuffix(strings.TrimPrefix(query.Data, HistoryQueryDataPrefix),
+		historyFailedQueryDataSuffix)
+	var offset, limit int32
+	if _, err := fmt.Sscanf(data, "%d-%d", &offset, &limit); err != nil {
+		log.Err(err).Msgf("Invalid history query data [queryData: %s].", query.Data)
+		h.sendErrorMessage(errors.New("invalid history page"), query)
+		return
+	}
+
+	text, markup, err := GenerateHistoryPage(h.queries, query.Message.Chat.ID, offset, limit, isFailedOnly)
+	if err != nil {
+		log.Err(err).Msgf("Unable to generate history page [telegramChatID: %v].", query.Message.Chat.ID)
+		h.sendErrorMessage(err, query)
+		return
+	}
+
+	// show loader
+	_ = h.botClient.SendCallbackConfig(query.ID, "")
+
+	// edit the previous history page in place
+	if err := h.botClient.SendEditMarkupMessage(context.Background(), query.Message.Chat.ID, query.Message.MessageID,
+		text,
+		markup); err != nil {
+		log.Err(err).Msgf("Unable to edit history page [user: %s].", query.From.UserName)
+		return
+	}
+}
+
+// GenerateHistoryPage lists a page of the chat's deliveries, newest first, or only those that failed to send.
+func GenerateHistoryPage(queries *sqlc.Queries, telegramChatID int64, offset int32, limit int32,
+	isFailedOnly bool) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	ctx := context.Background()
+	limit = min(max(limit, 1), MaxHistoryPageSize)
+	offset = max(offset, 0)
+
+	timeZone := time.UTC.String()
+	chat, err := queries.GetChat(ctx, telegramChatID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", nil, err
+	}
+	if err == nil {
+		timeZone = chat.TimeZone
+	}
+	loc := riverjobs.LoadLocation(timeZone)
+
+	// fetch one extra row to know whether there is an older page
+	deliveries, err := queries.GetDeliveriesByTelegramChatID(ctx, sqlc.GetDeliveriesByTelegramChatIDParams{
+		TelegramChatID: telegramChatID,
+		Status:         pgtype.Text{Valid: isFailedOnly, String: riverjobs.DeliveryStatusFailed},
+		Limit:          limit + 1,
+		Offset:         offset,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	hasOlder := len(deliveries) > int(limit)
+	if hasOlder {
+		deliveries = deliveries[:limit]
+	}
+
+	title, dataSuffix := "Delivery history", ""
+	if isFailedOnly {
+		title, dataSuffix = "Failed deliveries", historyFailedQueryDataSuffix
+	}
+
+	if len(deliveries) == 0 {
+		if isFailedOnly {
+			return "No reminders have failed to send.", nil, nil
+		}
+		return "No reminders have been delivered yet.", nil, nil
+	}
+
+	text := fmt.Sprintf("%s (%d to %d, newest first):\n\n", title, offset+1, offset+int32(len(deliveries)))
+	for _, delivery := range deliveries {
+		name := "Snoozed reminder"
+		if delivery.Name.Valid {
+			name = delivery.Name.String
+		}
+
+		statusText := "Pending ⏳"
+		switch delivery.Status {
+		case riverjobs.DeliveryStatusSent:
+			statusText = fmt.Sprintf("Sent ✅ (message ID: %v)", delivery.TelegramMessageID.Int64)
+		case riverjobs.DeliveryStatusFailed:
+			statusText = "Failed ❌"
+		case riverjobs.DeliveryStatusDropped:
+			statusText = "Dropped during quiet hours 🌙"
+		}
+
+		text += fmt.Sprintf("Delivery ID: %v\nJob name: %s\n", delivery.ID, name)
+		if delivery.FireAt.Valid {
+			text += fmt.Sprintf("Fire time: %s\n", riverjobs.FormatLocalTime(delivery.FireAt.Time, loc))
+		}
+		text += fmt.Sprintf("Status: %s\n", statusText)
+		if delivery.Status == riverjobs.DeliveryStatusFailed && delivery.Error.Valid {
+			text += fmt.Sprintf("Error: %s\n", delivery.Error.String)
+		}
+		if delivery.AcknowledgedAt.Valid {
+			text += fmt.Sprintf("Acknowledged: %s\n", riverjobs.FormatLocalTime(delivery.AcknowledgedAt.Time, loc))
+		}
+		text += "\n"
+	}
+
+	var buttons []tgbotapi.InlineKeyboardButton
+	if offset > 0 {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData("◀ Newer",
+			fmt.Sprintf("%s%d-%d%s", HistoryQueryDataPrefix, max(offset-limit, 0), limit, dataSuffix)))
+	}
+	if hasOlder {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData("Older ▶",
+			fmt.Sprintf("%s%d-%d%s", HistoryQueryDataPrefix, offset+limit, limit, dataSuffix)))
+	}
+	if len(buttons) == 0 {
+		return text, nil, nil
+	}
+	markup := tgbotapi.NewInlineKeyboardMarkup(buttons)
+	return text, &markup, nil
+}
@@ -0,0 +1,156 @@
+package callbackqueries
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"remembertelebot/calendars"
+	"remembertelebot/db/sqlc"
+)
+
+const MaxJobTags = 10
+
+// tags are kept short so that a tag filter still fits in the 64 bytes of callback query data.
+var tagRegex = regexp.MustCompile(`^[a-z0-9_-]{1,20}$`)
+
+// JobsFilter narrows down the jobs listed by /listjobs, all in Postgres. Whether a job fires today is matched on the
+// fire time of its pending occurrence, which is stored with the job whenever one is enqueued.
+type JobsFilter struct {
+	IsRecurring pgtype.Bool
+	Tag         string
+	FiresToday  bool
+}
+
+// ParseTags parses tags, with or without a leading #, into lowercase tags without duplicates.
+func ParseTags(words []string) ([]string, error) {
+	tags := make([]string, 0, len(words))
+	for _, word := range words {
+		tag := strings.ToLower(strings.TrimPrefix(word, "#"))
+		if !tagRegex.MatchString(tag) {
+			return nil, fmt.Errorf("tags may only have up to 20 lowercase letters, digits, - and _ [tag: %s]", word)
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > MaxJobTags {
+		return nil, fmt.Errorf("a job can have at most %d tags", MaxJobTags)
+	}
+	return tags, nil
+}
+
+// SplitNameTags splits the #tags at the end of a job name, e.g. "Pay rent #home #bills", from the name.
+func SplitNameTags(text string) (string, []string) {
+	words := strings.Fields(text)
+	i := len(words)
+	for i > 0 && strings.HasPrefix(words[i-1], "#") && tagRegex.MatchString(strings.ToLower(words[i-1][1:])) {
+		i--
+	}
+
+	tags, err := ParseTags(words[i:])
+	if err != nil {
+		// too many tags, so leave the name as it is
+		return text, nil
+	}
+	return strings.Join(words[:i], " "), tags
+}
+
+func FormatTags(tags []string) string {
+	return "#" + strings.Join(tags, " #")
+}
+
+// ParseJobsFilter parses the arguments of /listjobs: recurring or once, today, and a #tag.
+func ParseJobsFilter(args []string) (JobsFilter, error) {
+	var filter JobsFilter
+	for _, arg := range args {
+		switch arg = strings.ToLower(arg); {
+		case arg == "recurring":
+			filter.IsRecurring = pgtype.Bool{Valid: true, Bool: true}
+		case arg == "once":
+			filter.IsRecurring = pgtype.Bool{Valid: true, Bool: false}
+		case arg == "today":
+			filter.FiresToday = true
+		case strings.HasPrefix(arg, "#"):
+			tags, err := ParseTags([]string{arg})
+			if err != nil {
+				return JobsFilter{}, err
+			}
+			filter.Tag = tags[0]
+		default:
+			return JobsFilter{}, fmt.Errorf("unknown filter %s, please use recurring, once, today or #<tag>", arg)
+		}
+	}
+	return filter, nil
+}
+
+// queryData encodes the filter for callback query data as flags followed by the tag, e.g. rd#work, or "" without one.
+func (f JobsFilter) queryData() string {
+	var data string
+	if f.IsRecurring.Valid {
+		data = "o"
+		if f.IsRecurring.Bool {
+			data = "r"
+		}
+	}
+	if f.FiresToday {
+		data += "d"
+	}
+	if f.Tag != "" {
+		data += "#" + f.Tag
+	}
+	return data
+}
+
+func parseJobsFilterQueryData(data string) JobsFilter {
+	flags, tag, _ := strings.Cut(data, "#")
+	return JobsFilter{
+		IsRecurring: pgtype.Bool{Valid: strings.ContainsAny(flags, "ro"), Bool: strings.Contains(flags, "r")},
+		Tag:         tag,
+		FiresToday:  strings.Contains(flags, "d"),
+	}
+}
+
+// describe describes the jobs the filter keeps, e.g. "recurring, tagged #work and firing today", or "" without one.
+func (f JobsFilter) describe() string {
+	var parts []string
+	switch {
+	case f.IsRecurring.Valid && f.IsRecurring.Bool:
+		parts = append(parts, "recurring")
+	case f.IsRecurring.Valid:
+		parts = append(parts, "once-off")
+	}
+	if f.Tag != "" {
+		parts = append(parts, fmt.Sprintf("tagged #%s", f.Tag))
+	}
+	if f.FiresToday {
+		parts = append(parts, "firing today")
+	}
+	if len(parts) < 2 {
+		return strings.Join(parts, "")
+	}
+	return fmt.Sprintf("%s and %s", strings.Join(parts[:len(parts)-1], ", "), parts[len(parts)-1])
+}
+
+// setFiresTodayParams narrows the jobs page down to the jobs whose next reminder is due between now and the end of the
+// day, unless the whole day is skipped or excluded by one of their calendars. A job whose next reminder today was
+// skipped on its own is still listed, with the skip.
+func setFiresTodayParams(params *sqlc.GetActiveJobsPageByTelegramChatIDParams, now time.Time) {
+	endOfDay := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	params.FiresAfter = pgtype.Timestamp{Valid: true, Time: now.UTC()}
+	params.FiresBefore = pgtype.Timestamp{Valid: true, Time: endOfDay.UTC()}
+	params.FireDate = pgtype.Date{Valid: true, Time: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0,
+		time.UTC)}
+
+	// bundled calendars are not in Postgres, so the ones that exclude today are passed in
+	params.ExcludedCalendarNames = []string{}
+	for _, name := range calendars.BundledNames() {
+		if _, ok := calendars.BundledHoliday(name, now.Year(), now.Month(), now.Day()); ok {
+			params.ExcludedCalendarNames = append(params.ExcludedCalendarNames, name)
+		}
+	}
+}
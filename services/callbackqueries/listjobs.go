@@ -0,0 +1,442 @@
+package callbackqueries
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+
+	"remembertelebot/bot"
+	"remembertelebot/db/sqlc"
+	"remembertelebot/riverjobs"
+)
+
+// Query data of the /listjobs view is "jobs-page-<offset>-<filter>", or "jobs-<action>-<jobID>-<offset>-<filter>" for
+// the buttons of a job, so that the view can be edited back to the page it was on. Without a filter, the last dash is
+// left out.
+const (
+	JobsQueryDataPrefix              = "jobs-"
+	JobsPageQueryDataPrefix          = "jobs-page-"
+	JobsCancelQueryDataPrefix        = "jobs-cancel-"
+	JobsConfirmCancelQueryDataPrefix = "jobs-confirm-cancel-"
+	JobsPauseQueryDataPrefix         = "jobs-pause-"
+	JobsResumeQueryDataPrefix        = "jobs-resume-"
+	JobsEditQueryDataPrefix          = "jobs-edit-"
+	JobsNextRunsQueryDataPrefix      = "jobs-next-runs-"
+	JobsPageSize                     = 5
+	DefaultNextRuns                  = 5
+
+	// maxListedMessageLength keeps a page of jobs well within Telegram's 4096 character limit.
+	maxListedMessageLength = 200
+)
+
+func (h *Handler) processJobs(query *tgbotapi.CallbackQuery) {
+	var prefix string
+	for _, p := range []string{JobsPageQueryDataPrefix, JobsCancelQueryDataPrefix, JobsConfirmCancelQueryDataPrefix,
+		JobsPauseQueryDataPrefix, JobsResumeQueryDataPrefix, JobsEditQueryDataPrefix, JobsNextRunsQueryDataPrefix} {
+		if strings.HasPrefix(query.Data, p) {
+			prefix = p
+			break
+		}
+	}
+
+	if prefix == "" {
+		h.processDefault(query)
+		return
+	}
+
+	jobID, offset, filter, err := parseJobsQueryData(strings.TrimPrefix(query.Data, prefix),
+		prefix != JobsPageQueryDataPrefix)
+	if err != nil {
+		log.Err(err).Msgf("Invalid jobs query data [queryData: %s].", query.Data)
+		h.sendErrorMessage(errors.New("invalid jobs page"), query)
+		return
+	}
+
+	chatID := query.Message.Chat.ID
+	var status string
+	switch prefix {
+	case JobsCancelQueryDataPrefix:
+		h.processCancelJobPrompt(query, jobID, offset, filter)
+		return
+	case JobsEditQueryDataPrefix:
+		h.processEditJobPrompt(query, jobID)
+		return
+	case JobsNextRunsQueryDataPrefix:
+		h.processNextRuns(query, jobID)
+		return
+	case JobsConfirmCancelQueryDataPrefix:
+		status, err = CancelJob(h.riverClient, chatID, jobID)
+	case JobsPauseQueryDataPrefix, JobsResumeQueryDataPrefix:
+		status, _, err = SetJobPaused(h.queries, h.riverClient, chatID, jobID, prefix == JobsPauseQueryDataPrefix)
+	}
+	if err != nil {
+		log.Err(err).Msgf("Unable to update job from jobs page [queryData: %s].", query.Data)
+		h.sendErrorMessage(err, query)
+		return
+	}
+
+	text, markup, err := GenerateJobsPage(h.queries, chatID, offset, filter)
+	if err != nil {
+		log.Err(err).Msgf("Unable to generate jobs page [telegramChatID: %v].", chatID)
+		h.sendErrorMessage(err, query)
+		return
+	}
+	if status != "" {
+		text = fmt.Sprintf("%s\n\n%s", status, text)
+	}
+
+	// show loader
+	_ = h.botClient.SendCallbackConfig(query.ID, "")
+
+	// edit the previous jobs page in place
+	if err := h.botClient.SendEditMarkupMessage(context.Background(), chatID, query.Message.MessageID, text,
+		markup); err != nil {
+		log.Err(err).Msgf("Unable to edit jobs page [user: %s].", query.From.UserName)
+		return
+	}
+}
+
+// processCancelJobPrompt asks for confirmation in place of the jobs page before a job is cancelled.
+func (h *Handler) processCancelJobPrompt(query *tgbotapi.CallbackQuery, jobID int32, offset int32,
+	filter JobsFilter) {
+	job, err := GetOwnJob(h.queries, query.Message.Chat.ID, jobID)
+	if err != nil {
+		log.Err(err).Msgf("Unable to get job to cancel [jobID: %v].", jobID)
+		h.sendErrorMessage(err, query)
+		return
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("❌ Yes, cancel it",
+			jobQueryData(JobsConfirmCancelQueryDataPrefix, job.ID, offset, filter)),
+		tgbotapi.NewInlineKeyboardButtonData("◀ Back", pageQueryData(offset, filter)),
+	))
+
+	// show loader
+	_ = h.botClient.SendCallbackConfig(query.ID, "")
+
+	if err := h.botClient.SendEditMarkupMessage(context.Background(), query.Message.Chat.ID, query.Message.MessageID,
+		fmt.Sprintf("Cancel job %s (job ID: %v)? This cannot be undone.", job.Name, job.ID), &markup); err != nil {
+		log.Err(err).Msgf("Unable to edit jobs page to confirm cancellation [user: %s].", query.From.UserName)
+		return
+	}
+}
+
+func (h *Handler) processEditJobPrompt(query *tgbotapi.CallbackQuery, jobID int32) {
+	job, err := GetOwnJob(h.queries, query.Message.Chat.ID, jobID)
+	if err != nil {
+		log.Err(err).Msgf("Unable to get job to edit [jobID: %v].", jobID)
+		h.sendErrorMessage(err, query)
+		return
+	}
+
+	// show loader
+	_ = h.botClient.SendCallbackConfig(query.ID, "")
+
+	if _, err := h.botClient.SendMarkupMessage(context.Background(), query.Message.Chat.ID,
+		fmt.Sprintf("Select what to change for job %s.", job.Name), NewEditJobKeyboard(job.ID)); err != nil {
+		log.Err(err).Msgf("Unable to send job edit keyboard [user: %s][jobID: %v].", query.From.UserName, jobID)
+		return
+	}
+}
+
+func (h *Handler) processNextRuns(query *tgbotapi.CallbackQuery, jobID int32) {
+	text, err := GenerateNextRuns(h.queries, query.Message.Chat.ID, jobID, DefaultNextRuns)
+	if err != nil {
+		log.Err(err).Msgf("Unable to generate next runs [jobID: %v].", jobID)
+		h.sendErrorMessage(err, query)
+		return
+	}
+
+	// show loader
+	_ = h.botClient.SendCallbackConfig(query.ID, "")
+
+	if err := h.botClient.SendPlainMessage(context.Background(), query.Message.Chat.ID, text); err != nil {
+		log.Err(err).Msgf("Unable to send next runs [user: %s][jobID: %v].", query.From.UserName, jobID)
+		return
+	}
+}
+
+// GenerateJobsPage renders a page of the chat's active jobs that match the filter, starting at offset, with buttons to
+// act on each job and to page through the rest.
+func GenerateJobsPage(queries *sqlc.Queries, telegramChatID int64, offset int32, filter JobsFilter) (string,
+	*tgbotapi.InlineKeyboardMarkup, error) {
+	ctx := context.Background()
+	offset = max(offset, 0)
+	loc, err := chatLocation(queries, telegramChatID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// fetch one extra row to know whether there is a next page
+	params := sqlc.GetActiveJobsPageByTelegramChatIDParams{
+		TelegramChatID: telegramChatID,
+		IsRecurring:    filter.IsRecurring,
+		Tag:            pgtype.Text{Valid: filter.Tag != "", String: filter.Tag},
+		Limit:          JobsPageSize + 1,
+		Offset:         offset,
+	}
+	if filter.FiresToday {
+		setFiresTodayParams(&params, time.Now().In(loc))
+	}
+	jobs, err := queries.GetActiveJobsPageByTelegramChatID(ctx, params)
+	if err != nil {
+		return "", nil, err
+	}
+	hasNext := len(jobs) > JobsPageSize
+	if hasNext {
+		jobs = jobs[:JobsPageSize]
+	}
+
+	if len(jobs) == 0 {
+		// the last job of a later page was cancelled, so show the page before it
+		if offset > 0 {
+			return GenerateJobsPage(queries, telegramChatID, max(offset-JobsPageSize, 0), filter)
+		}
+		if description := filter.describe(); description != "" {
+			return fmt.Sprintf("You have no jobs that are %s. Input /listjobs to list all your jobs.", description), nil,
+				nil
+		}
+		return "You have no jobs yet. Input /newjob to create a new job.", nil, nil
+	}
+
+	now := time.Now().In(loc)
+	skips, err := queries.GetUpcomingJobSkipsByTelegramChatID(ctx, sqlc.GetUpcomingJobSkipsByTelegramChatIDParams{
+		TelegramChatID: telegramChatID,
+		SkipDate:       pgtype.Date{Valid: true, Time: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)},
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	skipTexts := make(map[int32][]string)
+	for _, skip := range skips {
+		skipText := fmt.Sprintf("%s (all day)", skip.SkipDate.Time.Format(time.DateOnly))
+		if skip.FireAt.Valid {
+			skipText = riverjobs.FormatLocalTime(skip.FireAt.Time, loc)
+		}
+		skipTexts[skip.JobID] = append(skipTexts[skip.JobID], skipText)
+	}
+
+	text := fmt.Sprintf("Your jobs (%d to %d):\n\n", offset+1, offset+int32(len(jobs)))
+	if description := filter.describe(); description != "" {
+		text = fmt.Sprintf("Your jobs that are %s (%d to %d):\n\n", description, offset+1, offset+int32(len(jobs)))
+	}
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, job := range jobs {
+		button := func(label string, prefix string) tgbotapi.InlineKeyboardButton {
+			return tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%s %v", label, job.ID),
+				jobQueryData(prefix, job.ID, offset, filter))
+		}
+		row := tgbotapi.NewInlineKeyboardRow(button("✏️", JobsEditQueryDataPrefix))
+
+		scheduleText := fmt.Sprintf("Once-off, at %s", riverjobs.FormatLocalTimestamp(job.Schedule, loc))
+		statusText := "Active"
+		if job.IsRecurring {
+			var endDate, maxOccurrences string
+			if job.EndsAt.Valid {
+				endDate = riverjobs.FormatEndDate(job.EndsAt.Time, loc)
+			}
+			if job.MaxOccurrences.Valid {
+				maxOccurrences = strconv.Itoa(int(job.MaxOccurrences.Int32))
+			}
+			recurrence := riverjobs.NewRecurrence(job.Schedule, job.IntervalSeconds, job.AnchorAt)
+			scheduleText = fmt.Sprintf("%s\nEnds: %s", riverjobs.DescribeRecurrence(recurrence, loc),
+				riverjobs.FormatJobEnd(endDate, maxOccurrences))
+			if len(job.CalendarNames) > 0 {
+				scheduleText += fmt.Sprintf("\nExcludes: %s", strings.Join(job.CalendarNames, ", "))
+			}
+			if job.PausedAt.Valid {
+				statusText = fmt.Sprintf("Paused ⏸ since %s", riverjobs.FormatLocalTime(job.PausedAt.Time, loc))
+				row = append(row, button("▶️", JobsResumeQueryDataPrefix))
+			} else {
+				occurrences, err := riverjobs.UpcomingOccurrences(ctx, queries, riverjobs.UpcomingJob{
+					ID:              job.ID,
+					TelegramChatID:  job.TelegramChatID,
+					Recurrence:      recurrence,
+					CalendarNames:   job.CalendarNames,
+					EndsAt:          job.EndsAt,
+					MaxOccurrences:  job.MaxOccurrences,
+					OccurrenceCount: job.OccurrenceCount,
+				}, loc, riverjobs.PreviewOccurrences)
+				if err != nil {
+					log.Warn().Err(err).Msgf("Unable to get upcoming occurrences [jobID: %v].", job.ID)
+				}
+				scheduleText += fmt.Sprintf("\nNext runs:\n%s", riverjobs.FormatOccurrences(occurrences, loc))
+				row = append(row, button("⏸", JobsPauseQueryDataPrefix))
+			}
+		}
+		row = append(row, button("📅", JobsNextRunsQueryDataPrefix), button("❌", JobsCancelQueryDataPrefix))
+		rows = append(rows, row)
+
+		if job.MisfirePolicy != riverjobs.MisfirePolicyOnce {
+			scheduleText += fmt.Sprintf("\nIf missed: %s", riverjobs.DescribeMisfirePolicy(job.MisfirePolicy))
+		}
+
+		if skipText, exists := skipTexts[job.ID]; exists {
+			scheduleText += fmt.Sprintf("\nSkipped: %s", strings.Join(skipText, ", "))
+		}
+
+		text += fmt.Sprintf("Job ID: %v\nJob name: %s\n", job.ID, job.Name)
+		if len(job.Tags) > 0 {
+			text += fmt.Sprintf("Tags: %s\n", FormatTags(job.Tags))
+		}
+		text += fmt.Sprintf("Message: %s\nSchedule: %s\nStatus: %s\n\n",
+			bot.DescribeMediaMessage(job.MediaType, truncateMessage(job.Message)), scheduleText, statusText)
+	}
+	text += "Tap ✏️ to edit, ⏸ to pause, ▶️ to resume, 📅 for the next runs of, or ❌ to cancel the job with that ID. " +
+		"Input /canceljobs to cancel several jobs at once."
+
+	var buttons []tgbotapi.InlineKeyboardButton
+	if offset > 0 {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData("◀ Previous",
+			pageQueryData(max(offset-JobsPageSize, 0), filter)))
+	}
+	if hasNext {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData("Next ▶",
+			pageQueryData(offset+JobsPageSize, filter)))
+	}
+	if len(buttons) > 0 {
+		rows = append(rows, buttons)
+	}
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return text, &markup, nil
+}
+
+// GenerateNextRuns lists the next count times a job owned by the chat will run.
+func GenerateNextRuns(queries *sqlc.Queries, telegramChatID int64, jobID int32, count int) (string, error) {
+	job, err := GetOwnJob(queries, telegramChatID, jobID)
+	if err != nil {
+		return "", err
+	}
+
+	loc, err := chatLocation(queries, telegramChatID)
+	if err != nil {
+		return "", err
+	}
+
+	if !job.IsRecurring {
+		return fmt.Sprintf("Job %s runs once, at %s.", job.Name, riverjobs.FormatLocalTimestamp(job.Schedule, loc)),
+			nil
+	}
+
+	occurrences, err := riverjobs.UpcomingOccurrences(context.Background(), queries, riverjobs.UpcomingJob{
+		ID:              job.ID,
+		TelegramChatID:  job.TelegramChatID,
+		Recurrence:      riverjobs.NewRecurrence(job.Schedule, job.IntervalSeconds, job.AnchorAt),
+		CalendarNames:   job.CalendarNames,
+		EndsAt:          job.EndsAt,
+		MaxOccurrences:  job.MaxOccurrences,
+		OccurrenceCount: job.OccurrenceCount,
+	}, loc, count)
+	if err != nil {
+		return "", fmt.Errorf("failed to get upcoming occurrences [jobID: %v]: %w", job.ID, err)
+	}
+
+	text := fmt.Sprintf("Next runs of job %s in %s:\n%s", job.Name, loc.String(),
+		riverjobs.FormatOccurrences(occurrences, loc))
+	if job.PausedAt.Valid {
+		text += fmt.Sprintf("\n\nThe job is paused, so these only happen once it is resumed with /resumejob-%v.",
+			job.ID)
+	}
+	return text, nil
+}
+
+// CancelJob cancels a job owned by the chat, returning the message to reply with.
+func CancelJob(riverClient *riverjobs.Client, telegramChatID int64, jobID int32) (string, error) {
+	name, err := riverClient.CancelJob(telegramChatID, jobID)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Successfully cancelled job: %s", name), nil
+}
+
+func pageQueryData(offset int32, filter JobsFilter) string {
+	data := fmt.Sprintf("%s%d", JobsPageQueryDataPrefix, offset)
+	if filterData := filter.queryData(); filterData != "" {
+		data += "-" + filterData
+	}
+	return data
+}
+
+func jobQueryData(prefix string, jobID int32, offset int32, filter JobsFilter) string {
+	data := fmt.Sprintf("%s%d-%d", prefix, jobID, offset)
+	if filterData := filter.queryData(); filterData != "" {
+		data += "-" + filterData
+	}
+	return data
+}
+
+// parseJobsQueryData parses the query data of the /listjobs view after its prefix, which starts with a job ID for the
+// buttons of a job.
+func parseJobsQueryData(data string, hasJobID bool) (int32, int32, JobsFilter, error) {
+	n := 2
+	if hasJobID {
+		n = 3
+	}
+	fields := strings.SplitN(data, "-", n)
+	if len(fields) < n-1 {
+		return 0, 0, JobsFilter{}, fmt.Errorf("expected at least %d fields, found %d", n-1, len(fields))
+	}
+
+	var filter JobsFilter
+	if len(fields) == n {
+		filter = parseJobsFilterQueryData(fields[n-1])
+	}
+
+	numbers := make([]int32, n-1)
+	for i := range numbers {
+		number, err := strconv.ParseInt(fields[i], 10, 32)
+		if err != nil {
+			return 0, 0, JobsFilter{}, err
+		}
+		numbers[i] = int32(number)
+	}
+	if hasJobID {
+		return numbers[0], numbers[1], filter, nil
+	}
+	return 0, numbers[0], filter, nil
+}
+
+// GetOwnJob gets a job by its ID, failing unless it belongs to the chat.
+func GetOwnJob(queries *sqlc.Queries, telegramChatID int64, jobID int32) (*sqlc.GetJobByIDRow, error) {
+	job, err := queries.GetJobByID(context.Background(), jobID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("job not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if job.TelegramChatID != telegramChatID {
+		log.Error().Msgf("Unauthorized job access [telegramChatID: %v][job: %+v].", telegramChatID, job)
+		return nil, errors.New("you can only manage your own jobs")
+	}
+	return &job, nil
+}
+
+func chatLocation(queries *sqlc.Queries, telegramChatID int64) (*time.Location, error) {
+	chat, err := queries.GetChat(context.Background(), telegramChatID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.UTC, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return riverjobs.LoadLocation(chat.TimeZone), nil
+}
+
+func truncateMessage(message string) string {
+	if utf8.RuneCountInString(message) <= maxListedMessageLength {
+		return message
+	}
+	return string([]rune(message)[:maxListedMessageLength]) + "…"
+}
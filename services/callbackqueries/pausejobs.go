@@ -0,0 +1,91 @@
+package callbackqueries
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+
+	"remembertelebot/db/sqlc"
+	"remembertelebot/riverjobs"
+)
+
+const (
+	PauseJobQueryDataPrefix  = "pause-job-"
+	ResumeJobQueryDataPrefix = "resume-job-"
+)
+
+func (h *Handler) processPauseJob(query *tgbotapi.CallbackQuery) {
+	paused := strings.HasPrefix(query.Data, PauseJobQueryDataPrefix)
+	jobIDStr := strings.TrimPrefix(strings.TrimPrefix(query.Data, PauseJobQueryDataPrefix), ResumeJobQueryDataPrefix)
+
+	var jobID int32
+	if _, err := fmt.Sscanf(jobIDStr, "%d", &jobID); err != nil {
+		log.Err(err).Msgf("Invalid job ID format [queryData: %s].", query.Data)
+		h.sendErrorMessage(errors.New("invalid job ID"), query)
+		return
+	}
+
+	text, markup, err := SetJobPaused(h.queries, h.riverClient, query.Message.Chat.ID, jobID, paused)
+	if err != nil {
+		log.Err(err).Msgf("Unable to update job paused state [jobID: %v][paused: %v].", jobID, paused)
+		h.sendErrorMessage(err, query)
+		return
+	}
+
+	// show loader
+	_ = h.botClient.SendCallbackConfig(query.ID, "")
+
+	if _, err := h.botClient.SendMarkupMessage(context.Background(), query.Message.Chat.ID, text, markup); err != nil {
+		log.Err(err).Msgf("Unable to send job paused state message [user: %s][jobID: %v].", query.From.UserName,
+			jobID)
+		return
+	}
+}
+
+// SetJobPaused pauses or resumes a recurring job owned by the chat, returning the message to reply with and a button
+// to undo the change.
+func SetJobPaused(queries *sqlc.Queries, riverClient *riverjobs.Client, telegramChatID int64, jobID int32,
+	paused bool) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	job, err := GetOwnJob(queries, telegramChatID, jobID)
+	if err != nil {
+		return "", nil, err
+	}
+	if !job.IsRecurring {
+		return "", nil, errors.New("only recurring jobs can be paused")
+	}
+
+	if paused {
+		if job.PausedAt.Valid {
+			return "", nil, fmt.Errorf("job %s is already paused", job.Name)
+		}
+		if _, err := riverClient.PauseJob(job.ID); err != nil {
+			return "", nil, err
+		}
+		markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(NewResumeJobButton(job.ID)))
+		return fmt.Sprintf("Paused job: %s. No reminders will be sent until you resume it.", job.Name), &markup, nil
+	}
+
+	if !job.PausedAt.Valid {
+		return "", nil, fmt.Errorf("job %s is not paused", job.Name)
+	}
+	if _, err := riverClient.ResumeJob(job.ID); err != nil {
+		return "", nil, err
+	}
+	markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(NewPauseJobButton(job.ID)))
+	return fmt.Sprintf("Resumed job: %s. Reminders will be sent from its next scheduled time.", job.Name), &markup,
+		nil
+}
+
+func NewPauseJobButton(jobID int32) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("⏸ Pause job %v", jobID),
+		fmt.Sprintf("%s%d", PauseJobQueryDataPrefix, jobID))
+}
+
+func NewResumeJobButton(jobID int32) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("▶️ Resume job %v", jobID),
+		fmt.Sprintf("%s%d", ResumeJobQueryDataPrefix, jobID))
+}
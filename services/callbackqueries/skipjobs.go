@@ -0,0 +1,105 @@
+package callbackqueries
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+
+	"remembertelebot/db/sqlc"
+	"remembertelebot/riverjobs"
+)
+
+// maxSkipLookahead bounds how many already skipped occurrences are stepped over to find the next one to skip.
+const maxSkipLookahead = 100
+
+func (h *Handler) processSkipNext(query *tgbotapi.CallbackQuery) {
+	_, jobID := riverjobs.ParseReminderQueryData(query.Data)
+	text, err := SkipJobOccurrence(h.queries, query.Message.Chat.ID, jobID, "")
+	if err != nil {
+		log.Err(err).Msgf("Unable to skip next job occurrence [jobID: %v].", jobID)
+		h.sendErrorMessage(err, query)
+		return
+	}
+
+	// show loader
+	_ = h.botClient.SendCallbackConfig(query.ID, "")
+
+	if err := h.botClient.SendPlainMessage(context.Background(), query.Message.Chat.ID, text); err != nil {
+		log.Err(err).Msgf("Unable to send success message for skipped occurrence [user: %s][jobID: %v].",
+			query.From.UserName, jobID)
+		return
+	}
+}
+
+// SkipJobOccurrence skips every occurrence of a recurring job on the given local date (YYYY-MM-DD), or only its next
+// occurrence that is not skipped yet if date is empty.
+func SkipJobOccurrence(queries *sqlc.Queries, telegramChatID int64, jobID int32, date string) (string, error) {
+	ctx := context.Background()
+	job, err := GetOwnJob(queries, telegramChatID, jobID)
+	if err != nil {
+		return "", err
+	}
+	if !job.IsRecurring {
+		return "", errors.New("only recurring jobs can be skipped")
+	}
+
+	chat, err := queries.GetChat(ctx, telegramChatID)
+	if err != nil {
+		return "", err
+	}
+	loc := riverjobs.LoadLocation(chat.TimeZone)
+
+	if date != "" {
+		skipDate, err := time.ParseInLocation(time.DateOnly, date, loc)
+		if err != nil {
+			return "", errors.New("please input the date in the format YYYY-MM-DD")
+		}
+		if now := time.Now().In(loc); skipDate.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0,
+			loc)) {
+			return "", errors.New("date must not be in the past")
+		}
+
+		if _, err := queries.CreateJobSkip(ctx, sqlc.CreateJobSkipParams{
+			JobID: job.ID,
+			SkipDate: pgtype.Date{Valid: true, Time: time.Date(skipDate.Year(), skipDate.Month(), skipDate.Day(), 0,
+				0, 0, 0, time.UTC)},
+		}); err != nil {
+			return "", fmt.Errorf("failed to create job skip [jobID: %v][date: %s]: %w", job.ID, date, err)
+		}
+		return fmt.Sprintf("Job %s will not be sent on %s.", job.Name, date), nil
+	}
+
+	recurrence := riverjobs.NewRecurrence(job.Schedule, job.IntervalSeconds, job.AnchorAt)
+	fireAt := time.Now()
+	for range maxSkipLookahead {
+		fireAt, err = riverjobs.NextPeriodicFireAt(recurrence, chat.TimeZone, fireAt)
+		if err != nil {
+			return "", err
+		}
+
+		isSkipped, err := riverjobs.IsOccurrenceSkipped(ctx, queries, job.ID, fireAt, loc)
+		if err != nil {
+			return "", err
+		}
+		if isSkipped {
+			continue
+		}
+
+		if _, err := queries.CreateJobSkip(ctx, sqlc.CreateJobSkipParams{
+			JobID:  job.ID,
+			FireAt: pgtype.Timestamp{Valid: true, Time: fireAt.UTC()},
+		}); err != nil {
+			return "", fmt.Errorf("failed to create job skip [jobID: %v][fireAt: %s]: %w", job.ID, fireAt.String(),
+				err)
+		}
+		return fmt.Sprintf("Job %s will not be sent at %s. Later occurrences will be sent as usual.", job.Name,
+			riverjobs.FormatLocalTime(fireAt, loc)), nil
+	}
+
+	return "", errors.New("too many upcoming occurrences are already skipped")
+}
@@ -0,0 +1,292 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+
+	"remembertelebot/calendars"
+	"remembertelebot/db/sqlc"
+	"remembertelebot/services/callbackqueries"
+)
+
+const maxJobCalendars = 5
+
+var nameRegex = regexp.MustCompile(`^[a-z0-9_-]{1,50}$`)
+
+func (h *Handler) processCalendars(message *tgbotapi.Message) {
+	ownCalendars, err := h.queries.GetCalendarsByTelegramChatID(context.Background(), message.Chat.ID)
+	if err != nil {
+		log.Err(err).Msgf("Unable to get calendars [telegramChatID: %v].", message.Chat.ID)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	text := "Public holiday calendars:\n"
+	for _, name := range calendars.BundledNames() {
+		text += fmt.Sprintf("• %s\n", name)
+	}
+
+	text += "\nYour calendars:\n"
+	if len(ownCalendars) == 0 {
+		text += "None yet.\n"
+	}
+	for _, calendar := range ownCalendars {
+		text += fmt.Sprintf("• %s (%d date(s))\n", calendar.Name, calendar.DateCount)
+	}
+
+	text += "\nTo add dates to a calendar (creating it if needed), input /calendar <name> add <YYYY-MM-DD> ...\n" +
+		"To remove dates, input /calendar <name> remove <YYYY-MM-DD> ...\n" +
+		"To view or delete a calendar, input /calendar <name> or /calendar <name> delete.\n\n" +
+		"To stop a recurring job from firing on a calendar's dates, input /excludejob-<jobID> <name> ..., for " +
+		"example /excludejob-123 holidays-sg team-offsites."
+	if err := h.botClient.SendPlainMessage(context.Background(), message.Chat.ID, text); err != nil {
+		log.Err(err).Msgf("Unable to respond to /calendars command [user: %s].", message.From.UserName)
+		return
+	}
+}
+
+func (h *Handler) processCalendar(message *tgbotapi.Message) {
+	ctx := context.Background()
+	args := strings.Fields(strings.ToLower(message.CommandArguments()))
+	if len(args) < 1 {
+		h.sendErrorMessage(errors.New("please input /calendar <name>, /calendar <name> add|remove <YYYY-MM-DD> ... "+
+			"or /calendar <name> delete"), message)
+		return
+	}
+
+	name := args[0]
+	if calendars.IsBundled(name) {
+		h.sendErrorMessage(fmt.Errorf("%s is a public holiday calendar and cannot be changed", name), message)
+		return
+	}
+	if !nameRegex.MatchString(name) {
+		h.sendErrorMessage(errors.New("calendar names may only have up to 50 lowercase letters, digits, - and _"),
+			message)
+		return
+	}
+
+	calendar, err := h.queries.GetCalendarByName(ctx, sqlc.GetCalendarByNameParams{
+		TelegramChatID: message.Chat.ID,
+		Name:           name,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Err(err).Msgf("Unable to get calendar [telegramChatID: %v][name: %s].", message.Chat.ID, name)
+		h.sendErrorMessage(err, message)
+		return
+	}
+	isFound := err == nil
+
+	var text string
+	switch {
+	case len(args) == 1:
+		if !isFound {
+			h.sendErrorMessage(fmt.Errorf("calendar %s not found", name), message)
+			return
+		}
+		text, err = h.describeCalendar(ctx, calendar.ID, name)
+	case args[1] == "delete" && len(args) == 2:
+		if !isFound {
+			h.sendErrorMessage(fmt.Errorf("calendar %s not found", name), message)
+			return
+		}
+		text, err = h.deleteCalendar(ctx, message.Chat.ID, calendar.ID, name)
+	case args[1] == "add" && len(args) > 2:
+		if !isFound {
+			newCalendar, createErr := h.queries.CreateCalendar(ctx, sqlc.CreateCalendarParams{
+				TelegramChatID: message.Chat.ID,
+				Name:           name,
+			})
+			if createErr != nil {
+				log.Err(createErr).Msgf("Unable to create calendar [telegramChatID: %v][name: %s].",
+					message.Chat.ID, name)
+				h.sendErrorMessage(createErr, message)
+				return
+			}
+			calendar.ID = newCalendar.ID
+		}
+		text, err = h.changeCalendarDates(ctx, calendar.ID, name, args[2:], true)
+	case args[1] == "remove" && len(args) > 2:
+		if !isFound {
+			h.sendErrorMessage(fmt.Errorf("calendar %s not found", name), message)
+			return
+		}
+		text, err = h.changeCalendarDates(ctx, calendar.ID, name, args[2:], false)
+	default:
+		h.sendErrorMessage(errors.New("please input /calendar <name>, /calendar <name> add|remove <YYYY-MM-DD> ... "+
+			"or /calendar <name> delete"), message)
+		return
+	}
+	if err != nil {
+		log.Err(err).Msgf("Unable to process calendar command [command: %s].", message.Text)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	if err := h.botClient.SendPlainMessage(ctx, message.Chat.ID, text); err != nil {
+		log.Err(err).Msgf("Unable to respond to /calendar command [user: %s].", message.From.UserName)
+		return
+	}
+}
+
+func (h *Handler) describeCalendar(ctx context.Context, calendarID int32, name string) (string, error) {
+	dates, err := h.queries.GetCalendarDates(ctx, calendarID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get calendar dates [calendarID: %v]: %w", calendarID, err)
+	}
+	if len(dates) == 0 {
+		return fmt.Sprintf("Calendar %s has no dates.", name), nil
+	}
+
+	dateTexts := make([]string, 0, len(dates))
+	for _, date := range dates {
+		dateTexts = append(dateTexts, date.Time.Format(time.DateOnly))
+	}
+	return fmt.Sprintf("Calendar %s excludes:\n%s", name, strings.Join(dateTexts, "\n")), nil
+}
+
+// changeCalendarDates adds or removes dates, given as YYYY-MM-DD, to or from a calendar.
+func (h *Handler) changeCalendarDates(ctx context.Context, calendarID int32, name string, dateTexts []string,
+	isAdd bool) (string, error) {
+	dates := make([]pgtype.Date, 0, len(dateTexts))
+	for _, dateText := range dateTexts {
+		date, err := time.Parse(time.DateOnly, dateText)
+		if err != nil {
+			return "", fmt.Errorf("please input dates in the format YYYY-MM-DD [date: %s]", dateText)
+		}
+		dates = append(dates, pgtype.Date{Valid: true, Time: date})
+	}
+
+	for _, date := range dates {
+		if isAdd {
+			if _, err := h.queries.AddCalendarDate(ctx, sqlc.AddCalendarDateParams{
+				CalendarID: calendarID,
+				Date:       date,
+			}); err != nil {
+				return "", fmt.Errorf("failed to add calendar date [calendarID: %v][date: %v]: %w", calendarID,
+					date.Time, err)
+			}
+		} else if _, err := h.queries.RemoveCalendarDate(ctx, sqlc.RemoveCalendarDateParams{
+			CalendarID: calendarID,
+			Date:       date,
+		}); err != nil {
+			return "", fmt.Errorf("failed to remove calendar date [calendarID: %v][date: %v]: %w", calendarID,
+				date.Time, err)
+		}
+	}
+
+	if isAdd {
+		return fmt.Sprintf("Successfully added %d date(s) to calendar %s.", len(dates), name), nil
+	}
+	return fmt.Sprintf("Successfully removed %d date(s) from calendar %s.", len(dates), name), nil
+}
+
+// deleteCalendar deletes a calendar and stops the chat's jobs from excluding its dates.
+func (h *Handler) deleteCalendar(ctx context.Context, chatID int64, calendarID int32, name string) (string, error) {
+	jobs, err := h.queries.GetActiveJobsByTelegramChatID(ctx, chatID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get active jobs [telegramChatID: %v]: %w", chatID, err)
+	}
+	for _, job := range jobs {
+		if !slices.Contains(job.CalendarNames, name) {
+			continue
+		}
+		if _, err := h.queries.UpdateJobCalendars(ctx, sqlc.UpdateJobCalendarsParams{
+			CalendarNames: slices.DeleteFunc(job.CalendarNames, func(calendarName string) bool {
+				return calendarName == name
+			}),
+			ID: job.ID,
+		}); err != nil {
+			return "", fmt.Errorf("failed to update job calendars [jobID: %v]: %w", job.ID, err)
+		}
+	}
+
+	if _, err := h.queries.DeleteCalendar(ctx, calendarID); err != nil {
+		return "", fmt.Errorf("failed to delete calendar [calendarID: %v]: %w", calendarID, err)
+	}
+	return fmt.Sprintf("Successfully deleted calendar %s.", name), nil
+}
+
+func (h *Handler) processExcludeJob(message *tgbotapi.Message) {
+	ctx := context.Background()
+	command := message.Text
+	args := strings.Fields(strings.ToLower(strings.TrimPrefix(command, "/excludejob-")))
+	if len(args) < 2 {
+		log.Error().Msgf("Invalid exclude job arguments [command: %s].", command)
+		h.sendErrorMessage(errors.New("please input /excludejob-<jobID> <calendar> ... or /excludejob-<jobID> off"),
+			message)
+		return
+	}
+
+	var jobID int32
+	if _, err := fmt.Sscanf(args[0], "%d", &jobID); err != nil {
+		log.Err(err).Msgf("Invalid job ID format [command: %s].", command)
+		h.sendErrorMessage(errors.New("please provide a valid numeric job ID"), message)
+		return
+	}
+
+	job, err := callbackqueries.GetOwnJob(h.queries, message.Chat.ID, jobID)
+	if err != nil {
+		log.Err(err).Msgf("Unable to get job [jobID: %v].", jobID)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	if !job.IsRecurring {
+		h.sendErrorMessage(errors.New("only recurring jobs can exclude calendars"), message)
+		return
+	}
+
+	calendarNames := []string{}
+	if !(len(args) == 2 && args[1] == "off") {
+		for _, name := range args[1:] {
+			if slices.Contains(calendarNames, name) {
+				continue
+			}
+			if !calendars.IsBundled(name) {
+				if _, err := h.queries.GetCalendarByName(ctx, sqlc.GetCalendarByNameParams{
+					TelegramChatID: message.Chat.ID,
+					Name:           name,
+				}); err != nil {
+					log.Warn().Err(err).Msgf("Unable to get calendar [telegramChatID: %v][name: %s].",
+						message.Chat.ID, name)
+					h.sendErrorMessage(fmt.Errorf("calendar %s not found, see /calendars", name), message)
+					return
+				}
+			}
+			calendarNames = append(calendarNames, name)
+		}
+		if len(calendarNames) > maxJobCalendars {
+			h.sendErrorMessage(fmt.Errorf("a job can exclude at most %d calendars", maxJobCalendars), message)
+			return
+		}
+	}
+
+	if _, err := h.queries.UpdateJobCalendars(ctx, sqlc.UpdateJobCalendarsParams{
+		CalendarNames: calendarNames,
+		ID:            job.ID,
+	}); err != nil {
+		log.Err(err).Msgf("Unable to update job calendars [jobID: %v][calendarNames: %v].", job.ID, calendarNames)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	text := fmt.Sprintf("Job %s no longer excludes any calendars.", job.Name)
+	if len(calendarNames) > 0 {
+		text = fmt.Sprintf("Job %s will not fire on dates in %s.", job.Name, strings.Join(calendarNames, ", "))
+	}
+	if err := h.botClient.SendPlainMessage(ctx, message.Chat.ID, text); err != nil {
+		log.Err(err).Msgf("Unable to send success message for job exclusion [user: %s][jobID: %v].",
+			message.From.UserName, job.ID)
+		return
+	}
+}
@@ -5,24 +5,51 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cohesion-org/deepseek-go"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/rs/zerolog/log"
 
 	"remembertelebot/bot"
 	"remembertelebot/db/sqlc"
 	"remembertelebot/ristrettocache"
 	"remembertelebot/riverjobs"
-	"remembertelebot/services/messages"
+	"remembertelebot/services/callbackqueries"
 )
 
 const (
-	StartCommand     = "start"
-	NewJobCommand    = "newjob"
-	ListJobsCommand  = "listjobs"
-	CancelJobCommand = "canceljob"
+	StartCommand          = "start"
+	NewJobCommand         = "newjob"
+	ListJobsCommand       = "listjobs"
+	CancelJobCommand      = "canceljob"
+	CancelAllCommand      = "cancelall"
+	CancelJobsCommand     = "canceljobs"
+	TimeZoneCommand       = "timezone"
+	NagJobCommand         = "nagjob"
+	HistoryCommand        = "history"
+	PauseJobCommand       = "pausejob"
+	ResumeJobCommand      = "resumejob"
+	EditJobCommand        = "editjob"
+	SkipNextCommand       = "skipnext"
+	NextRunsCommand       = "nextruns"
+	QuietHoursCommand     = "quiethours"
+	CalendarsCommand      = "calendars"
+	CalendarCommand       = "calendar"
+	ExcludeJobCommand     = "excludejob"
+	MisfireJobCommand     = "misfirejob"
+	TagJobCommand         = "tag"
+	FindJobCommand        = "findjob"
+	CloneJobCommand       = "clonejob"
+	SaveTemplateCommand   = "savetemplate"
+	UseTemplateCommand    = "usetemplate"
+	DeleteTemplateCommand = "deletetemplate"
+
+	maxNextRuns  = 20
+	maxFoundJobs = 20
 )
 
 type Handler struct {
@@ -54,10 +81,52 @@ func (h *Handler) ProcessCommand(update tgbotapi.Update) {
 		h.processStart(update.Message)
 	case command == NewJobCommand:
 		h.processNewJob(update.Message)
+	case command == CloneJobCommand:
+		h.processCloneJob(update.Message)
+	case command == SaveTemplateCommand:
+		h.processSaveTemplate(update.Message)
+	case command == UseTemplateCommand:
+		h.processUseTemplate(update.Message)
+	case command == DeleteTemplateCommand:
+		h.processDeleteTemplate(update.Message)
 	case command == ListJobsCommand:
 		h.processListJobs(update.Message)
 	case command == CancelJobCommand:
 		h.processCancelJob(update.Message)
+	case command == CancelAllCommand:
+		h.processCancelAll(update.Message)
+	case command == CancelJobsCommand:
+		h.processCancelJobs(update.Message)
+	case command == TimeZoneCommand:
+		h.processTimeZone(update.Message)
+	case command == QuietHoursCommand:
+		h.processQuietHours(update.Message)
+	case command == CalendarsCommand:
+		h.processCalendars(update.Message)
+	case command == CalendarCommand:
+		h.processCalendar(update.Message)
+	case command == ExcludeJobCommand:
+		h.processExcludeJob(update.Message)
+	case command == NagJobCommand:
+		h.processNagJob(update.Message)
+	case command == MisfireJobCommand:
+		h.processMisfireJob(update.Message)
+	case command == TagJobCommand:
+		h.processTagJob(update.Message)
+	case command == FindJobCommand:
+		h.processFindJob(update.Message)
+	case command == HistoryCommand:
+		h.processHistory(update.Message)
+	case command == EditJobCommand:
+		h.processEditJob(update.Message)
+	case command == SkipNextCommand:
+		h.processSkipNext(update.Message)
+	case command == NextRunsCommand:
+		h.processNextRuns(update.Message)
+	case command == PauseJobCommand:
+		h.processPauseJob(update.Message, true)
+	case command == ResumeJobCommand:
+		h.processPauseJob(update.Message, false)
 	default:
 		h.processDefault(update.Message)
 	}
@@ -74,20 +143,52 @@ func (h *Handler) processStart(message *tgbotapi.Message) {
 		"2. Recurring reminders - Great for regular tasks that need to be done periodically (or you'll be dismembered periodically)\n\n" +
 		"Available commands:\n" +
 		"/start - Show this help menu\n" +
-		"/newjob - Create a new reminder job\n" +
-		"/listjobs - List all your active reminder jobs\n" +
-		"/canceljob-<jobID> - Cancel a specific job (e.g. /canceljob-123)\n\n" +
+		"/newjob - Create a new reminder job, whose message can also be a photo, document, voice note or sticker\n" +
+		"/listjobs - Page through your active reminder jobs, with buttons to edit, pause, resume or cancel each job; " +
+		"add recurring, once, today or #<tag> to filter them (e.g. /listjobs recurring #work)\n" +
+		"/findjob <text> - Search the names, messages and tags of your jobs\n" +
+		"/tag-<jobID> <tag> ... - Tag a job (e.g. /tag-123 work urgent), or /tag-<jobID> off to remove its tags\n" +
+		"/canceljob-<jobID> - Cancel a specific job (e.g. /canceljob-123)\n" +
+		"/canceljobs - Select several jobs to cancel at once\n" +
+		"/cancelall - Cancel all your jobs\n" +
+		"/clonejob-<jobID> - Create a new job with the name, message and type of an existing one (e.g. " +
+		"/clonejob-123)\n" +
+		"/savetemplate <name> <jobID> - Save a job as a reusable template, or the job being created with /newjob " +
+		"if no job ID is given (e.g. /savetemplate invoice 123)\n" +
+		"/usetemplate <name> - Create a new job from a template (e.g. /usetemplate invoice), or list your " +
+		"templates\n" +
+		"/deletetemplate <name> - Delete a template\n" +
+		"/editjob-<jobID> - Change the name, message or schedule of a job (e.g. /editjob-123)\n" +
+		"/skipnext-<jobID> <date> - Skip the next occurrence of a recurring job, or every occurrence on a date " +
+		"(e.g. /skipnext-123 or /skipnext-123 2025-12-25)\n" +
+		"/nextruns-<jobID> <count> - List the next times a job will run (e.g. /nextruns-123 10)\n" +
+		"/calendars - List public holiday calendars and your own calendars of dates to exclude\n" +
+		"/calendar <name> add <date> - Add dates to your own calendar (e.g. /calendar offsites add 2025-12-24)\n" +
+		"/excludejob-<jobID> <calendar> - Stop a recurring job from firing on a calendar's dates (e.g. " +
+		"/excludejob-123 holidays-sg)\n" +
+		"/pausejob-<jobID> - Pause a recurring job until you resume it (e.g. /pausejob-123)\n" +
+		"/resumejob-<jobID> - Resume a paused recurring job (e.g. /resumejob-123)\n" +
+		"/timezone <timeZone> - View or set your time zone (e.g. /timezone Asia/Singapore)\n" +
+		"/quiethours <HH:MM-HH:MM> <policy> - View or set hours in which reminders are deferred, dropped or sent " +
+		"silently (e.g. /quiethours 22:00-07:00 defer), or /quiethours off to stop\n" +
+		"/nagjob-<jobID> <minutes> <times> - Re-send a reminder every few minutes until you tap Done (e.g. " +
+		"/nagjob-123 10 5), or /nagjob-<jobID> off to stop\n" +
+		"/misfirejob-<jobID> <policy> - Choose whether reminders missed while I was unavailable are skipped, sent " +
+		"once or all sent with a summary (e.g. /misfirejob-123 all)\n" +
+		"/history <count> - View your most recently delivered reminders, <count> per page (e.g. /history 20); add " +
+		"failed to only view those that failed to send, with why (e.g. /history failed)\n\n" +
 		"To create a new job, use /newjob and follow the prompts to set up your reminder. " +
 		"Remember, I'm watching... always watching... 👀"
 
-	if err := h.botClient.SendPlainMessage(message.Chat.ID, startText); err != nil {
+	if err := h.botClient.SendPlainMessage(context.Background(), message.Chat.ID, startText); err != nil {
 		log.Err(err).Msgf("Unable to respond to /start command [user: %s].", message.From.UserName)
 		return
 	}
 }
 
 func (h *Handler) processDefault(message *tgbotapi.Message) {
-	if err := h.botClient.SendPlainMessage(message.Chat.ID, "Received unknown command."); err != nil {
+	if err := h.botClient.SendPlainMessage(context.Background(), message.Chat.ID,
+		"Received unknown command."); err != nil {
 		log.Err(err).Msgf("Unable to respond to unknown command [user: %s].", message.From.UserName)
 		return
 	}
@@ -109,74 +210,467 @@ func (h *Handler) processCancelJob(message *tgbotapi.Message) {
 		return
 	}
 
-	job, err := h.queries.GetJobByID(context.Background(), jobID)
+	text, err := callbackqueries.CancelJob(h.riverClient, message.Chat.ID, jobID)
+	if err != nil {
+		log.Err(err).Msgf("Unable to cancel job [jobID: %v].", jobID)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	if err := h.botClient.SendPlainMessage(context.Background(), message.Chat.ID, text); err != nil {
+		log.Err(err).Msgf("Unable to send success message for job cancellation [user: %s][jobID: %v].", message.From.UserName, jobID)
+		return
+	}
+}
+
+func (h *Handler) processCancelAll(message *tgbotapi.Message) {
+	text, markup, err := callbackqueries.GenerateCancelAllPrompt(h.queries, message.Chat.ID)
+	if err != nil {
+		log.Err(err).Msgf("Unable to generate cancel all prompt [telegramChatID: %v].", message.Chat.ID)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	if _, err := h.botClient.SendMarkupMessage(context.Background(), message.Chat.ID, text, markup); err != nil {
+		log.Err(err).Msgf("Unable to respond to /cancelall command [user: %s].", message.From.UserName)
+		return
+	}
+}
+
+func (h *Handler) processCancelJobs(message *tgbotapi.Message) {
+	text, markup, err := callbackqueries.GenerateCancelJobsSelection(h.queries, message.Chat.ID, nil)
+	if err != nil {
+		log.Err(err).Msgf("Unable to generate job cancellation selection [telegramChatID: %v].", message.Chat.ID)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	if _, err := h.botClient.SendMarkupMessage(context.Background(), message.Chat.ID, text, markup); err != nil {
+		log.Err(err).Msgf("Unable to respond to /canceljobs command [user: %s].", message.From.UserName)
+		return
+	}
+}
+
+func (h *Handler) processEditJob(message *tgbotapi.Message) {
+	command := message.Text
+	var jobID int32
+	if _, err := fmt.Sscanf(strings.TrimPrefix(command, "/editjob-"), "%d", &jobID); err != nil {
+		log.Err(err).Msgf("Invalid job ID format [command: %s].", command)
+		h.sendErrorMessage(errors.New("please provide a valid numeric job ID"), message)
+		return
+	}
+
+	job, err := callbackqueries.GetOwnJob(h.queries, message.Chat.ID, jobID)
 	if err != nil {
 		log.Err(err).Msgf("Unable to get job [jobID: %v].", jobID)
 		h.sendErrorMessage(err, message)
 		return
 	}
 
-	if job.TelegramChatID != message.Chat.ID {
-		log.Err(err).Msgf("Unauthorized job cancellation [telegramChatID: %v][job: %+v].", message.Chat.ID, job)
-		h.sendErrorMessage(errors.New("you can only cancel your own jobs"), message)
+	if _, err := h.botClient.SendMarkupMessage(context.Background(), message.Chat.ID,
+		fmt.Sprintf("Select what to change for job %s.",
+			job.Name), callbackqueries.NewEditJobKeyboard(job.ID)); err != nil {
+		log.Err(err).Msgf("Unable to respond to /editjob command [user: %s].", message.From.UserName)
 		return
 	}
+}
 
-	if job.IsRecurring {
-		h.riverClient.CancelPeriodicJob(job.RiverJobID.Int64)
-	} else {
-		if err := h.riverClient.CancelScheduledJob(job.RiverJobID.Int64); err != nil {
-			log.Err(err).Msgf("Unable to cancel scheduled job on river [riverJobID: %v].", job.RiverJobID.Int64)
-			h.sendErrorMessage(err, message)
+func (h *Handler) processSkipNext(message *tgbotapi.Message) {
+	command := message.Text
+	args := strings.Fields(strings.TrimPrefix(command, "/skipnext-"))
+	if len(args) < 1 || len(args) > 2 {
+		log.Error().Msgf("Invalid skip next arguments [command: %s].", command)
+		h.sendErrorMessage(errors.New("please input /skipnext-<jobID> or /skipnext-<jobID> <YYYY-MM-DD>"), message)
+		return
+	}
+
+	var jobID int32
+	if _, err := fmt.Sscanf(args[0], "%d", &jobID); err != nil {
+		log.Err(err).Msgf("Invalid job ID format [command: %s].", command)
+		h.sendErrorMessage(errors.New("please provide a valid numeric job ID"), message)
+		return
+	}
+
+	var date string
+	if len(args) == 2 {
+		date = args[1]
+	}
+
+	text, err := callbackqueries.SkipJobOccurrence(h.queries, message.Chat.ID, jobID, date)
+	if err != nil {
+		log.Err(err).Msgf("Unable to skip job occurrence [jobID: %v][date: %s].", jobID, date)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	if err := h.botClient.SendPlainMessage(context.Background(), message.Chat.ID, text); err != nil {
+		log.Err(err).Msgf("Unable to send success message for skipped occurrence [user: %s][jobID: %v].",
+			message.From.UserName, jobID)
+		return
+	}
+}
+
+func (h *Handler) processNextRuns(message *tgbotapi.Message) {
+	command := message.Text
+	args := strings.Fields(strings.TrimPrefix(command, "/nextruns-"))
+	if len(args) < 1 || len(args) > 2 {
+		log.Error().Msgf("Invalid next runs arguments [command: %s].", command)
+		h.sendErrorMessage(errors.New("please input /nextruns-<jobID> or /nextruns-<jobID> <count>"), message)
+		return
+	}
+
+	var jobID int32
+	if _, err := fmt.Sscanf(args[0], "%d", &jobID); err != nil {
+		log.Err(err).Msgf("Invalid job ID format [command: %s].", command)
+		h.sendErrorMessage(errors.New("please provide a valid numeric job ID"), message)
+		return
+	}
+
+	count := callbackqueries.DefaultNextRuns
+	if len(args) == 2 {
+		if _, err := fmt.Sscanf(args[1], "%d", &count); err != nil || count < 1 || count > maxNextRuns {
+			h.sendErrorMessage(fmt.Errorf("please provide a count between 1 and %d", maxNextRuns), message)
 			return
 		}
 	}
 
-	if _, err := h.queries.DeleteJobByID(context.Background(), job.ID); err != nil {
-		log.Err(err).Msgf("Unable to delete job in db [jobID: %v].", jobID)
+	text, err := callbackqueries.GenerateNextRuns(h.queries, message.Chat.ID, jobID, count)
+	if err != nil {
+		log.Err(err).Msgf("Unable to generate next runs [jobID: %v].", jobID)
 		h.sendErrorMessage(err, message)
 		return
 	}
 
-	if err := h.botClient.SendPlainMessage(message.Chat.ID, fmt.Sprintf("Successfully cancelled job: %s", job.Name)); err != nil {
-		log.Err(err).Msgf("Unable to send success message for job cancellation [user: %s][jobID: %v].", message.From.UserName, jobID)
+	if err := h.botClient.SendPlainMessage(context.Background(), message.Chat.ID, text); err != nil {
+		log.Err(err).Msgf("Unable to respond to /nextruns command [user: %s].", message.From.UserName)
 		return
 	}
 }
 
-func (h *Handler) processListJobs(message *tgbotapi.Message) {
-	jobs, err := h.queries.GetActiveJobsByTelegramChatID(context.Background(), message.Chat.ID)
+func (h *Handler) processPauseJob(message *tgbotapi.Message, paused bool) {
+	command := message.Text
+	prefix := "/resumejob-"
+	if paused {
+		prefix = "/pausejob-"
+	}
+
+	var jobID int32
+	if _, err := fmt.Sscanf(strings.TrimPrefix(command, prefix), "%d", &jobID); err != nil {
+		log.Err(err).Msgf("Invalid job ID format [command: %s].", command)
+		h.sendErrorMessage(errors.New("please provide a valid numeric job ID"), message)
+		return
+	}
+
+	text, markup, err := callbackqueries.SetJobPaused(h.queries, h.riverClient, message.Chat.ID, jobID, paused)
 	if err != nil {
-		log.Err(err).Msgf("Unable to get active jobs [telegramChatID: %v].", message.Chat.ID)
+		log.Err(err).Msgf("Unable to update job paused state [jobID: %v][paused: %v].", jobID, paused)
 		h.sendErrorMessage(err, message)
 		return
 	}
 
-	var jobsText string
-	if len(jobs) == 0 {
-		jobsText = "You have no jobs yet. Input /newjob to create a new job."
-	} else {
-		for _, job := range jobs {
-			scheduleText := fmt.Sprintf("Once-off, at UTC %s", job.Schedule)
-			if job.IsRecurring {
-				scheduleText = fmt.Sprintf("Recurring at UTC %s (%s)", job.Schedule, messages.GetCronDescriptor(job.Schedule))
-			}
-
-			jobText := fmt.Sprintf("Job ID: %v\nJob name: %s\nMessage: %s\nSchedule: %s\n\n", job.ID, job.Name, job.Message, scheduleText)
-			jobsText += jobText
+	if _, err := h.botClient.SendMarkupMessage(context.Background(), message.Chat.ID, text, markup); err != nil {
+		log.Err(err).Msgf("Unable to send success message for job paused state [user: %s][jobID: %v].",
+			message.From.UserName, jobID)
+		return
+	}
+}
+
+func (h *Handler) processNagJob(message *tgbotapi.Message) {
+	command := message.Text
+	args := strings.Fields(strings.TrimPrefix(command, "/nagjob-"))
+	if len(args) < 2 || len(args) > 3 {
+		log.Error().Msgf("Invalid nag job arguments [command: %s].", command)
+		h.sendErrorMessage(errors.New("please input /nagjob-<jobID> <minutes> <times> or /nagjob-<jobID> off"),
+			message)
+		return
+	}
+
+	var jobID int32
+	if _, err := fmt.Sscanf(args[0], "%d", &jobID); err != nil {
+		log.Err(err).Msgf("Invalid job ID format [command: %s].", command)
+		h.sendErrorMessage(errors.New("please provide a valid numeric job ID"), message)
+		return
+	}
+
+	var nagInterval, nagMaxCount pgtype.Int4
+	if !strings.EqualFold(args[1], "off") {
+		if len(args) != 3 {
+			h.sendErrorMessage(errors.New("please provide both the minutes between reminders and the number of times"),
+				message)
+			return
 		}
+		minutes, err := strconv.Atoi(args[1])
+		if err != nil || minutes < 1 || minutes > 24*60 {
+			h.sendErrorMessage(errors.New("minutes between reminders must be between 1 and 1440"), message)
+			return
+		}
+		times, err := strconv.Atoi(args[2])
+		if err != nil || times < 1 || times > 100 {
+			h.sendErrorMessage(errors.New("number of times must be between 1 and 100"), message)
+			return
+		}
+		nagInterval = pgtype.Int4{Valid: true, Int32: int32(minutes)}
+		nagMaxCount = pgtype.Int4{Valid: true, Int32: int32(times)}
+	}
+
+	job, err := callbackqueries.GetOwnJob(h.queries, message.Chat.ID, jobID)
+	if err != nil {
+		log.Err(err).Msgf("Unable to get job [jobID: %v].", jobID)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	if _, err := h.queries.UpdateJobNagPolicy(context.Background(), sqlc.UpdateJobNagPolicyParams{
+		NagIntervalMinutes: nagInterval,
+		NagMaxCount:        nagMaxCount,
+		ID:                 job.ID,
+	}); err != nil {
+		log.Err(err).Msgf("Unable to update job nag policy [jobID: %v].", jobID)
+		h.sendErrorMessage(err, message)
+		return
+	}
 
-		jobsText += "To cancel a job, " +
-			"input the command /canceljob-<jobID> where jobID is the ID of the job you want to cancel.\n\nFor example, " +
-			"if jobID is 123, you would input /canceljob-123."
+	text := fmt.Sprintf("Reminders for job %s will no longer be re-sent.", job.Name)
+	if nagInterval.Valid {
+		text = fmt.Sprintf("Reminders for job %s will be re-sent every %d minute(s), up to %d time(s), until you "+
+			"tap Done.", job.Name, nagInterval.Int32, nagMaxCount.Int32)
+	}
+	if err := h.botClient.SendPlainMessage(context.Background(), message.Chat.ID, text); err != nil {
+		log.Err(err).Msgf("Unable to send success message for job nag update [user: %s][jobID: %v].",
+			message.From.UserName, jobID)
+		return
+	}
+}
+
+func (h *Handler) processMisfireJob(message *tgbotapi.Message) {
+	command := message.Text
+	args := strings.Fields(strings.ToLower(strings.TrimPrefix(command, "/misfirejob-")))
+	if len(args) != 2 {
+		log.Error().Msgf("Invalid misfire job arguments [command: %s].", command)
+		h.sendErrorMessage(errors.New("please input /misfirejob-<jobID> skip, once or all"), message)
+		return
 	}
 
-	if err := h.botClient.SendPlainMessage(message.Chat.ID, jobsText); err != nil {
+	var jobID int32
+	if _, err := fmt.Sscanf(args[0], "%d", &jobID); err != nil {
+		log.Err(err).Msgf("Invalid job ID format [command: %s].", command)
+		h.sendErrorMessage(errors.New("please provide a valid numeric job ID"), message)
+		return
+	}
+
+	policy := args[1]
+	switch policy {
+	case riverjobs.MisfirePolicySkip, riverjobs.MisfirePolicyOnce, riverjobs.MisfirePolicyAll:
+	default:
+		h.sendErrorMessage(errors.New("misfire policy must be skip, once or all"), message)
+		return
+	}
+
+	job, err := callbackqueries.GetOwnJob(h.queries, message.Chat.ID, jobID)
+	if err != nil {
+		log.Err(err).Msgf("Unable to get job [jobID: %v].", jobID)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	if _, err := h.queries.UpdateJobMisfirePolicy(context.Background(), sqlc.UpdateJobMisfirePolicyParams{
+		MisfirePolicy: policy,
+		ID:            job.ID,
+	}); err != nil {
+		log.Err(err).Msgf("Unable to update job misfire policy [jobID: %v][policy: %s].", jobID, policy)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	if err := h.botClient.SendPlainMessage(context.Background(), message.Chat.ID, fmt.Sprintf("Job %s will %s.",
+		job.Name,
+		riverjobs.DescribeMisfirePolicy(policy))); err != nil {
+		log.Err(err).Msgf("Unable to send success message for job misfire update [user: %s][jobID: %v].",
+			message.From.UserName, jobID)
+		return
+	}
+}
+
+func (h *Handler) processHistory(message *tgbotapi.Message) {
+	limit := int32(callbackqueries.DefaultHistoryPageSize)
+	isFailedOnly := false
+	for _, arg := range strings.Fields(message.CommandArguments()) {
+		if strings.EqualFold(arg, "failed") {
+			isFailedOnly = true
+			continue
+		}
+		if _, err := fmt.Sscanf(arg, "%d", &limit); err != nil || limit < 1 ||
+			limit > callbackqueries.MaxHistoryPageSize {
+			h.sendErrorMessage(fmt.Errorf("please provide a count between 1 and %d, optionally with failed",
+				callbackqueries.MaxHistoryPageSize), message)
+			return
+		}
+	}
+
+	text, markup, err := callbackqueries.GenerateHistoryPage(h.queries, message.Chat.ID, 0, limit, isFailedOnly)
+	if err != nil {
+		log.Err(err).Msgf("Unable to generate history page [telegramChatID: %v].", message.Chat.ID)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	if _, err := h.botClient.SendMarkupMessage(context.Background(), message.Chat.ID, text, markup); err != nil {
+		log.Err(err).Msgf("Unable to respond to /history command [user: %s].", message.From.UserName)
+		return
+	}
+}
+
+func (h *Handler) processListJobs(message *tgbotapi.Message) {
+	filter, err := callbackqueries.ParseJobsFilter(strings.Fields(message.CommandArguments()))
+	if err != nil {
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	text, markup, err := callbackqueries.GenerateJobsPage(h.queries, message.Chat.ID, 0, filter)
+	if err != nil {
+		log.Err(err).Msgf("Unable to generate jobs page [telegramChatID: %v].", message.Chat.ID)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	if _, err := h.botClient.SendMarkupMessage(context.Background(), message.Chat.ID, text, markup); err != nil {
 		log.Err(err).Msgf("Unable to respond to /listjobs command [user: %s].", message.From.UserName)
 		return
 	}
 }
 
+func (h *Handler) processTimeZone(message *tgbotapi.Message) {
+	ctx := context.Background()
+	chat, err := h.queries.GetChat(ctx, message.Chat.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Err(err).Msgf("Unable to get chat [telegramChatID: %v].", message.Chat.ID)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	timeZone := strings.TrimSpace(message.CommandArguments())
+	if timeZone == "" {
+		currentTimeZone := time.UTC.String()
+		if err == nil {
+			currentTimeZone = chat.TimeZone
+		}
+		text := fmt.Sprintf("Your time zone is %s.\n\nTo change it, input the command /timezone <timeZone> where "+
+			"timeZone is an IANA time zone name.\n\nFor example, if you live in Singapore, you would input /timezone "+
+			"Asia/Singapore.", currentTimeZone)
+		if err := h.botClient.SendPlainMessage(ctx, message.Chat.ID, text); err != nil {
+			log.Err(err).Msgf("Unable to respond to /timezone command [user: %s].", message.From.UserName)
+		}
+		return
+	}
+
+	loc, locErr := time.LoadLocation(timeZone)
+	if locErr != nil || strings.EqualFold(timeZone, "local") {
+		log.Warn().Err(locErr).Msgf("Invalid time zone [command: %s].", message.Text)
+		h.sendErrorMessage(errors.New("please provide a valid IANA time zone (e.g. Asia/Singapore)"), message)
+		return
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := h.queries.CreateChat(ctx, message.Chat.ID); err != nil {
+			log.Err(err).Msgf("Unable to create chat [telegramChatID: %v].", message.Chat.ID)
+			h.sendErrorMessage(err, message)
+			return
+		}
+	}
+
+	if _, err := h.queries.UpdateChatTimeZone(ctx, sqlc.UpdateChatTimeZoneParams{
+		TelegramChatID: message.Chat.ID,
+		TimeZone:       loc.String(),
+	}); err != nil {
+		log.Err(err).Msgf("Unable to update chat time zone [telegramChatID: %v][timeZone: %s].", message.Chat.ID,
+			loc.String())
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	if err := h.riverClient.ReschedulePeriodicJobs(message.Chat.ID); err != nil {
+		log.Err(err).Msgf("Unable to reschedule periodic jobs [telegramChatID: %v].", message.Chat.ID)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	if err := h.botClient.SendPlainMessage(ctx, message.Chat.ID, fmt.Sprintf("Successfully set time zone to %s. "+
+		"Recurring reminders will now follow this time zone.", loc.String())); err != nil {
+		log.Err(err).Msgf("Unable to send success message for time zone update [user: %s].", message.From.UserName)
+		return
+	}
+}
+
+func (h *Handler) processQuietHours(message *tgbotapi.Message) {
+	ctx := context.Background()
+	chat, err := h.queries.GetChat(ctx, message.Chat.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Err(err).Msgf("Unable to get chat [telegramChatID: %v].", message.Chat.ID)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	args := strings.TrimSpace(message.CommandArguments())
+	if args == "" {
+		text := "You have no quiet hours."
+		if quietHours, ok := riverjobs.NewQuietHours(chat.QuietHoursStart, chat.QuietHoursEnd,
+			chat.QuietHoursPolicy); ok {
+			text = fmt.Sprintf("Your quiet hours are %s in %s.", quietHours.String(), chat.TimeZone)
+		}
+		text += "\n\nTo change them, input the command /quiethours <HH:MM-HH:MM> <policy> where policy is one of:\n" +
+			"defer - hold reminders until the quiet hours end (default)\n" +
+			"drop - do not send reminders at all\n" +
+			"silent - send reminders without a notification sound\n\n" +
+			"For example, /quiethours 22:00-07:00 defer. To stop, input /quiethours off."
+		if err := h.botClient.SendPlainMessage(ctx, message.Chat.ID, text); err != nil {
+			log.Err(err).Msgf("Unable to respond to /quiethours command [user: %s].", message.From.UserName)
+		}
+		return
+	}
+
+	var quietHours riverjobs.QuietHours
+	params := sqlc.UpdateChatQuietHoursParams{
+		QuietHoursPolicy: riverjobs.QuietHoursPolicyDefer,
+		TelegramChatID:   message.Chat.ID,
+	}
+	if !strings.EqualFold(args, "off") {
+		if quietHours, err = riverjobs.ParseQuietHours(args); err != nil {
+			log.Warn().Err(err).Msgf("Invalid quiet hours [command: %s].", message.Text)
+			h.sendErrorMessage(err, message)
+			return
+		}
+		params.QuietHoursStart = quietHours.StartInt4()
+		params.QuietHoursEnd = quietHours.EndInt4()
+		params.QuietHoursPolicy = quietHours.Policy
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := h.queries.CreateChat(ctx, message.Chat.ID); err != nil {
+			log.Err(err).Msgf("Unable to create chat [telegramChatID: %v].", message.Chat.ID)
+			h.sendErrorMessage(err, message)
+			return
+		}
+	}
+
+	if _, err := h.queries.UpdateChatQuietHours(ctx, params); err != nil {
+		log.Err(err).Msgf("Unable to update chat quiet hours [telegramChatID: %v][params: %+v].", message.Chat.ID,
+			params)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	text := "Successfully turned off quiet hours."
+	if params.QuietHoursStart.Valid {
+		text = fmt.Sprintf("Successfully set quiet hours to %s.", quietHours.String())
+	}
+	if err := h.botClient.SendPlainMessage(ctx, message.Chat.ID, text); err != nil {
+		log.Err(err).Msgf("Unable to send success message for quiet hours update [user: %s].",
+			message.From.UserName)
+		return
+	}
+}
+
 func (h *Handler) processNewJob(message *tgbotapi.Message) {
 	ctx := context.Background()
 	_, err := h.queries.GetChat(ctx, message.Chat.ID)
@@ -203,15 +697,16 @@ func (h *Handler) processNewJob(message *tgbotapi.Message) {
 		}
 	}
 
-	if err := h.botClient.SendPlainMessage(message.Chat.ID, "Please enter a name for your job."); err != nil {
+	if err := h.botClient.SendPlainMessage(ctx, message.Chat.ID, "Please enter a name for your job. To tag it, end the name with #tags, e.g. "+
+		"Pay rent #home #bills."); err != nil {
 		log.Err(err).Msgf("Unable to respond to /newjob command [user: %s].", message.From.UserName)
 		return
 	}
 }
 
 func (h *Handler) sendErrorMessage(err error, message *tgbotapi.Message) {
-	if err := h.botClient.SendPlainMessage(message.Chat.ID, fmt.Sprintf("An error occurred processing the command: %v",
-		err.Error())); err != nil {
+	if err := h.botClient.SendPlainMessage(context.Background(), message.Chat.ID,
+		fmt.Sprintf("An error occurred processing the command: %v", err.Error())); err != nil {
 		log.Warn().Err(err).Msgf("Unable to publish error message [user: %s][message: %v].", message.From.UserName,
 			err.Error())
 	}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog/log"
+
+	"remembertelebot/db/sqlc"
+	"remembertelebot/services/callbackqueries"
+)
+
+func (h *Handler) processTagJob(message *tgbotapi.Message) {
+	command := message.Text
+	args := strings.Fields(strings.TrimPrefix(command, "/tag-"))
+	if len(args) < 2 {
+		log.Error().Msgf("Invalid tag arguments [command: %s].", command)
+		h.sendErrorMessage(errors.New("please input /tag-<jobID> <tag> ... or /tag-<jobID> off"), message)
+		return
+	}
+
+	var jobID int32
+	if _, err := fmt.Sscanf(args[0], "%d", &jobID); err != nil {
+		log.Err(err).Msgf("Invalid job ID format [command: %s].", command)
+		h.sendErrorMessage(errors.New("please provide a valid numeric job ID"), message)
+		return
+	}
+
+	tags := []string{}
+	if len(args) != 2 || strings.ToLower(args[1]) != "off" {
+		var err error
+		if tags, err = callbackqueries.ParseTags(args[1:]); err != nil {
+			h.sendErrorMessage(err, message)
+			return
+		}
+	}
+
+	job, err := callbackqueries.GetOwnJob(h.queries, message.Chat.ID, jobID)
+	if err != nil {
+		log.Err(err).Msgf("Unable to get job [jobID: %v].", jobID)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	if _, err := h.queries.UpdateJobTags(context.Background(), sqlc.UpdateJobTagsParams{
+		Tags: tags,
+		ID:   job.ID,
+	}); err != nil {
+		log.Err(err).Msgf("Unable to update job tags [jobID: %v][tags: %v].", job.ID, tags)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	text := fmt.Sprintf("Removed the tags of job %s.", job.Name)
+	if len(tags) > 0 {
+		text = fmt.Sprintf("Tagged job %s with %s. Input /listjobs #%s to list the jobs with a tag.", job.Name,
+			callbackqueries.FormatTags(tags), tags[0])
+	}
+	if err := h.botClient.SendPlainMessage(context.Background(), message.Chat.ID, text); err != nil {
+		log.Err(err).Msgf("Unable to respond to /tag command [user: %s].", message.From.UserName)
+		return
+	}
+}
+
+func (h *Handler) processFindJob(message *tgbotapi.Message) {
+	query := strings.TrimSpace(message.CommandArguments())
+	if query == "" {
+		h.sendErrorMessage(errors.New("please input /findjob <text> to search the names, messages and tags of your "+
+			"jobs"), message)
+		return
+	}
+
+	// fetch one extra row to know whether there are more matches
+	jobs, err := h.queries.SearchJobsByTelegramChatID(context.Background(), sqlc.SearchJobsByTelegramChatIDParams{
+		TelegramChatID: message.Chat.ID,
+		Query:          strings.TrimPrefix(query, "#"),
+		Limit:          maxFoundJobs + 1,
+	})
+	if err != nil {
+		log.Err(err).Msgf("Unable to search jobs [telegramChatID: %v][query: %s].", message.Chat.ID, query)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	text := fmt.Sprintf("No jobs match %s.", query)
+	if len(jobs) > 0 {
+		text = fmt.Sprintf("Jobs matching %s:\n", query)
+	}
+	for i, job := range jobs {
+		if i == maxFoundJobs {
+			text += fmt.Sprintf("\nOnly the first %d matches are shown, please narrow down your search.", maxFoundJobs)
+			break
+		}
+
+		jobType := "once-off"
+		switch {
+		case job.PausedAt.Valid:
+			jobType = "recurring, paused"
+		case job.IsRecurring:
+			jobType = "recurring"
+		}
+		text += fmt.Sprintf("• %v: %s (%s)", job.ID, job.Name, jobType)
+		if len(job.Tags) > 0 {
+			text += " " + callbackqueries.FormatTags(job.Tags)
+		}
+		text += "\n"
+	}
+	if len(jobs) > 0 {
+		text += "\nInput /nextruns-<jobID>, /editjob-<jobID> or /canceljob-<jobID> to manage a job."
+	}
+
+	if err := h.botClient.SendPlainMessage(context.Background(), message.Chat.ID, text); err != nil {
+		log.Err(err).Msgf("Unable to respond to /findjob command [user: %s].", message.From.UserName)
+		return
+	}
+}
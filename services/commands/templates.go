@@ -0,0 +1,288 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+
+	"remembertelebot/bot"
+	"remembertelebot/db/sqlc"
+	"remembertelebot/services/callbackqueries"
+)
+
+func (h *Handler) processCloneJob(message *tgbotapi.Message) {
+	command := message.Text
+	jobIDStr := strings.TrimSpace(strings.TrimPrefix(command, "/clonejob-"))
+	var jobID int32
+	if _, err := fmt.Sscanf(jobIDStr, "%d", &jobID); err != nil {
+		log.Err(err).Msgf("Invalid job ID format [command: %s].", command)
+		h.sendErrorMessage(errors.New("please provide a valid numeric job ID"), message)
+		return
+	}
+
+	job, err := callbackqueries.GetOwnJob(h.queries, message.Chat.ID, jobID)
+	if err != nil {
+		log.Err(err).Msgf("Unable to get job [jobID: %v].", jobID)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	h.startJobFromTemplate(message, job.Name, job.Message, bot.Media{Type: job.MediaType, FileID: job.MediaFileID},
+		pgtype.Bool{Valid: true, Bool: job.IsRecurring}, job.IntervalSeconds.Valid)
+}
+
+func (h *Handler) processSaveTemplate(message *tgbotapi.Message) {
+	ctx := context.Background()
+	args := strings.Fields(message.CommandArguments())
+	if len(args) < 1 || len(args) > 2 {
+		h.sendErrorMessage(errors.New("please input /savetemplate <name> while creating a job with /newjob, or "+
+			"/savetemplate <name> <jobID>"), message)
+		return
+	}
+
+	name := strings.ToLower(args[0])
+	if !nameRegex.MatchString(name) {
+		h.sendErrorMessage(errors.New("template names may only have up to 50 lowercase letters, digits, - and _"),
+			message)
+		return
+	}
+
+	params := sqlc.SaveTemplateParams{
+		TelegramChatID: message.Chat.ID,
+		Name:           name,
+	}
+	if len(args) == 2 {
+		var jobID int32
+		if _, err := fmt.Sscanf(args[1], "%d", &jobID); err != nil {
+			log.Err(err).Msgf("Invalid job ID format [command: %s].", message.Text)
+			h.sendErrorMessage(errors.New("please provide a valid numeric job ID"), message)
+			return
+		}
+
+		job, err := callbackqueries.GetOwnJob(h.queries, message.Chat.ID, jobID)
+		if err != nil {
+			log.Err(err).Msgf("Unable to get job [jobID: %v].", jobID)
+			h.sendErrorMessage(err, message)
+			return
+		}
+
+		params.JobName = job.Name
+		params.Message = job.Message
+		params.MediaType = job.MediaType
+		params.MediaFileID = job.MediaFileID
+		params.IsRecurring = pgtype.Bool{Valid: true, Bool: job.IsRecurring}
+		params.IsInterval = job.IntervalSeconds.Valid
+	} else {
+		chat, err := h.queries.GetChat(ctx, message.Chat.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			log.Err(err).Msgf("Unable to get chat [telegramChatID: %v].", message.Chat.ID)
+			h.sendErrorMessage(err, message)
+			return
+		}
+
+		var chatContextMap map[string]string
+		if err == nil {
+			if err := json.Unmarshal(chat.Context, &chatContextMap); err != nil {
+				log.Err(err).Msgf("Unable to unmarshal chat context [chat: %+v].", chat)
+				h.sendErrorMessage(err, message)
+				return
+			}
+		}
+
+		// only a job being created through /newjob has a name and message without a job ID to edit
+		_, isEdit := chatContextMap["edit_job_id"]
+		hasMessage := chatContextMap["message"] != "" || chatContextMap["media_file_id"] != ""
+		if chatContextMap["name"] == "" || !hasMessage || isEdit {
+			h.sendErrorMessage(errors.New("please enter the name and message of a job with /newjob first, or input "+
+				"/savetemplate <name> <jobID>"), message)
+			return
+		}
+
+		params.JobName = chatContextMap["name"]
+		params.Message = chatContextMap["message"]
+		params.MediaType = chatContextMap["media_type"]
+		params.MediaFileID = chatContextMap["media_file_id"]
+		if isRecurring, err := strconv.ParseBool(chatContextMap["is_recurring"]); err == nil {
+			params.IsRecurring = pgtype.Bool{Valid: true, Bool: isRecurring}
+		}
+		params.IsInterval = chatContextMap["is_interval"] == "true"
+	}
+
+	if _, err := h.queries.SaveTemplate(ctx, params); err != nil {
+		log.Err(err).Msgf("Unable to save template [params: %+v].", params)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	if err := h.botClient.SendPlainMessage(ctx, message.Chat.ID, fmt.Sprintf("Successfully saved template %s. Input "+
+		"/usetemplate %s to create a job from it.", name, name)); err != nil {
+		log.Err(err).Msgf("Unable to respond to /savetemplate command [user: %s].", message.From.UserName)
+		return
+	}
+}
+
+func (h *Handler) processUseTemplate(message *tgbotapi.Message) {
+	ctx := context.Background()
+	name := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	if name == "" {
+		h.processListTemplates(message)
+		return
+	}
+
+	template, err := h.queries.GetTemplateByName(ctx, sqlc.GetTemplateByNameParams{
+		TelegramChatID: message.Chat.ID,
+		Name:           name,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		h.sendErrorMessage(fmt.Errorf("template %s not found, input /usetemplate to list your templates", name),
+			message)
+		return
+	}
+	if err != nil {
+		log.Err(err).Msgf("Unable to get template [telegramChatID: %v][name: %s].", message.Chat.ID, name)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	h.startJobFromTemplate(message, template.JobName, template.Message, bot.Media{Type: template.MediaType,
+		FileID: template.MediaFileID}, template.IsRecurring, template.IsInterval)
+}
+
+func (h *Handler) processListTemplates(message *tgbotapi.Message) {
+	templates, err := h.queries.GetTemplatesByTelegramChatID(context.Background(), message.Chat.ID)
+	if err != nil {
+		log.Err(err).Msgf("Unable to get templates [telegramChatID: %v].", message.Chat.ID)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	text := "You have no templates yet.\n"
+	if len(templates) > 0 {
+		text = "Your templates:\n"
+	}
+	for _, template := range templates {
+		text += fmt.Sprintf("• %s - %s (%s): %s\n", template.Name, template.JobName,
+			describeTemplateType(template.IsRecurring, template.IsInterval),
+			bot.DescribeMediaMessage(template.MediaType, template.Message))
+	}
+	text += "\nTo save a template, input /savetemplate <name> while creating a job with /newjob, or /savetemplate " +
+		"<name> <jobID> to save an existing job.\nTo create a job from a template, input /usetemplate <name>.\n" +
+		"To delete a template, input /deletetemplate <name>."
+
+	if err := h.botClient.SendPlainMessage(context.Background(), message.Chat.ID, text); err != nil {
+		log.Err(err).Msgf("Unable to respond to /usetemplate command [user: %s].", message.From.UserName)
+		return
+	}
+}
+
+func (h *Handler) processDeleteTemplate(message *tgbotapi.Message) {
+	name := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	if name == "" {
+		h.sendErrorMessage(errors.New("please input /deletetemplate <name>"), message)
+		return
+	}
+
+	rows, err := h.queries.DeleteTemplate(context.Background(), sqlc.DeleteTemplateParams{
+		TelegramChatID: message.Chat.ID,
+		Name:           name,
+	})
+	if err != nil {
+		log.Err(err).Msgf("Unable to delete template [telegramChatID: %v][name: %s].", message.Chat.ID, name)
+		h.sendErrorMessage(err, message)
+		return
+	}
+	if rows == 0 {
+		h.sendErrorMessage(fmt.Errorf("template %s not found", name), message)
+		return
+	}
+
+	if err := h.botClient.SendPlainMessage(context.Background(), message.Chat.ID,
+		fmt.Sprintf("Successfully deleted template %s.", name)); err != nil {
+		log.Err(err).Msgf("Unable to respond to /deletetemplate command [user: %s].", message.From.UserName)
+		return
+	}
+}
+
+func describeTemplateType(isRecurring pgtype.Bool, isInterval bool) string {
+	switch {
+	case !isRecurring.Valid:
+		return "any schedule"
+	case isInterval:
+		return "every interval"
+	case isRecurring.Bool:
+		return "recurring"
+	default:
+		return "once-off"
+	}
+}
+
+// startJobFromTemplate pre-fills the /newjob chat context with a job's name, message and media and, if known, its
+// type, and then asks for what is still missing.
+func (h *Handler) startJobFromTemplate(message *tgbotapi.Message, name string, text string, media bot.Media,
+	isRecurring pgtype.Bool, isInterval bool) {
+	ctx := context.Background()
+	chat, err := h.queries.GetChat(ctx, message.Chat.ID)
+	if err != nil {
+		log.Err(err).Msgf("Unable to get chat [telegramChatID: %v].", message.Chat.ID)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	contextMap := map[string]string{"name": name, "message": text}
+	if !media.IsZero() {
+		contextMap["media_type"] = media.Type
+		contextMap["media_file_id"] = media.FileID
+	}
+	if isRecurring.Valid {
+		contextMap["is_recurring"] = strconv.FormatBool(isRecurring.Bool)
+		if isInterval {
+			contextMap["is_interval"] = "true"
+		}
+	}
+	contextMapBytes, err := json.Marshal(contextMap)
+	if err != nil {
+		log.Err(err).Msgf("Unable to marshal chat context [contextMap: %+v].", contextMap)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	if _, err := h.queries.UpdateChatContext(ctx, sqlc.UpdateChatContextParams{
+		TelegramChatID: message.Chat.ID,
+		Context:        contextMapBytes,
+	}); err != nil {
+		log.Err(err).Msgf("Unable to update chat context [telegramChatID: %v][context: %+v].", message.Chat.ID,
+			contextMap)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	intro := fmt.Sprintf("Creating a new job named %s with the message:\n%s", name,
+		bot.DescribeMediaMessage(media.Type, text))
+	if !isRecurring.Valid {
+		if err := h.botClient.SendPlainMessage(ctx, message.Chat.ID, intro); err != nil {
+			log.Err(err).Msgf("Unable to send new job details [user: %s].", message.From.UserName)
+			return
+		}
+		if err := h.botClient.SendHtmlMessage(ctx, message.Chat.ID, "Select message schedule type.",
+			callbackqueries.NewJobTypeKeyboard()); err != nil {
+			log.Err(err).Msgf("Unable to send html message [telegramChatID: %v].", message.Chat.ID)
+			h.sendErrorMessage(err, message)
+		}
+		return
+	}
+
+	if err := h.botClient.SendPlainMessage(ctx, message.Chat.ID, fmt.Sprintf("%s\n\n%s", intro,
+		callbackqueries.SchedulePrompt(callbackqueries.JobType(isRecurring.Bool, isInterval),
+			chat.TimeZone))); err != nil {
+		log.Err(err).Msgf("Unable to send request for schedule [user: %s].", message.From.UserName)
+		return
+	}
+}
@@ -0,0 +1,114 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+
+	"remembertelebot/bot"
+	"remembertelebot/db/sqlc"
+	"remembertelebot/riverjobs"
+	"remembertelebot/services/callbackqueries"
+)
+
+func (h *Handler) processEditJob(message *tgbotapi.Message, contextMap map[string]string, timeZone string) {
+	ctx := context.Background()
+	loc := riverjobs.LoadLocation(timeZone)
+
+	jobID, err := strconv.Atoi(contextMap["edit_job_id"])
+	if err != nil {
+		log.Err(err).Msgf("Invalid edit job ID in chat context [contextMap: %+v].", contextMap)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	job, err := callbackqueries.GetOwnJob(h.queries, message.Chat.ID, int32(jobID))
+	if err != nil {
+		log.Err(err).Msgf("Unable to get job [jobID: %v].", jobID)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	name, text, schedule := job.Name, job.Message, job.Schedule
+	intervalSeconds, anchorAt := job.IntervalSeconds, job.AnchorAt
+	switch contextMap["edit_field"] {
+	case callbackqueries.EditJobFieldName:
+		if name, err = validateJobName(message.Text); err != nil {
+			h.sendErrorMessage(err, message)
+			return
+		}
+	case callbackqueries.EditJobFieldMessage:
+		if text, err = validateJobMessage(message.Text); err != nil {
+			h.sendErrorMessage(err, message)
+			return
+		}
+	case callbackqueries.EditJobFieldSchedule:
+		if job.IsRecurring {
+			intervalSeconds, anchorAt = pgtype.Int4{}, pgtype.Timestamp{}
+			if recurrence, err := riverjobs.ParseInterval(message.Text, loc); err == nil {
+				schedule = recurrence.CronTab
+				intervalSeconds, anchorAt = recurrence.IntervalSeconds(), recurrence.AnchorTimestamp()
+			} else if schedule, err = validateCronTab(message.Text); err != nil {
+				schedule = h.useAI(message, loc.String())
+				if schedule == "" {
+					return
+				}
+			}
+
+			if err := validateJobReschedule(riverjobs.UpcomingJob{
+				Recurrence:      riverjobs.NewRecurrence(schedule, intervalSeconds, anchorAt),
+				EndsAt:          job.EndsAt,
+				MaxOccurrences:  job.MaxOccurrences,
+				OccurrenceCount: job.OccurrenceCount,
+			}, loc); err != nil {
+				h.sendErrorMessage(err, message)
+				return
+			}
+		} else {
+			ts, err := validateScheduleTimestamp(message.Text, loc)
+			if err != nil {
+				schedule = h.useAIForTimestamp(message, loc)
+				if schedule == "" {
+					return
+				}
+			} else {
+				schedule = ts.Format(time.DateTime)
+			}
+		}
+	default:
+		h.processDefault(message, "Unable to trace message context.")
+		return
+	}
+
+	updatedJob, err := h.riverClient.UpdateJob(job.ID, name, text, schedule, intervalSeconds, anchorAt)
+	if err != nil {
+		log.Err(err).Msgf("Unable to update job [jobID: %v].", job.ID)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	if _, err := h.queries.UpdateChatContext(ctx, sqlc.UpdateChatContextParams{
+		TelegramChatID: message.Chat.ID,
+		Context:        []byte("{}"),
+	}); err != nil {
+		log.Err(err).Msgf("Unable to update empty chat context [telegramChatID: %v].", message.Chat.ID)
+	}
+
+	scheduleText := fmt.Sprintf("Once-off, at %s", riverjobs.FormatLocalTimestamp(updatedJob.Schedule, loc))
+	if updatedJob.IsRecurring {
+		scheduleText = riverjobs.DescribeRecurrence(riverjobs.NewRecurrence(updatedJob.Schedule, updatedJob.IntervalSeconds,
+			updatedJob.AnchorAt), loc)
+	}
+	if err := h.botClient.SendPlainMessage(ctx, message.Chat.ID, fmt.Sprintf("Successfully updated job %v.\n\n"+
+		"Job name: %s\nMessage: %s\nSchedule: %s", updatedJob.ID, updatedJob.Name,
+		bot.DescribeMediaMessage(updatedJob.MediaType, updatedJob.Message), scheduleText)); err != nil {
+		log.Err(err).Msgf("Unable to send success message for job edit [user: %s][jobID: %v].",
+			message.From.UserName, job.ID)
+		return
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	deepseek "github.com/cohesion-org/deepseek-go"
@@ -16,21 +18,24 @@ import (
 	"remembertelebot/db/sqlc"
 	"remembertelebot/deepseekai"
 	"remembertelebot/ristrettocache"
+	"remembertelebot/riverjobs"
 	"remembertelebot/services/callbackqueries"
 )
 
 type Handler struct {
 	botClient      *bot.Client
 	queries        *sqlc.Queries
+	riverClient    *riverjobs.Client
 	deepSeekClient *deepseekai.Client
 	cache          *ristrettocache.Cache[[]deepseek.ChatCompletionMessage]
 }
 
-func NewHandler(botClient *bot.Client, queries *sqlc.Queries, deepSeekClient *deepseekai.Client,
-	cache *ristrettocache.Cache[[]deepseek.ChatCompletionMessage]) *Handler {
+func NewHandler(botClient *bot.Client, queries *sqlc.Queries, riverClient *riverjobs.Client,
+	deepSeekClient *deepseekai.Client, cache *ristrettocache.Cache[[]deepseek.ChatCompletionMessage]) *Handler {
 	return &Handler{
 		botClient:      botClient,
 		queries:        queries,
+		riverClient:    riverClient,
 		deepSeekClient: deepSeekClient,
 		cache:          cache,
 	}
@@ -53,11 +58,6 @@ func (h *Handler) ProcessMessage(message *tgbotapi.Message) {
 		return
 	}
 
-	if message.Text == "" {
-		h.processDefault(message, "Message format not recognised.")
-		return
-	}
-
 	var chatContextMap map[string]string
 	if err := json.Unmarshal(chat.Context, &chatContextMap); err != nil {
 		log.Err(err).Msgf("Unable to unmarshal chat context [chat: %+v].", chat)
@@ -65,21 +65,52 @@ func (h *Handler) ProcessMessage(message *tgbotapi.Message) {
 		return
 	}
 
+	_, isSnooze := chatContextMap["snooze_message"]
+	_, isEdit := chatContextMap["edit_job_id"]
+	_, hasName := chatContextMap["name"]
+	_, hasMessage := chatContextMap["message"]
+	// only the message of a new job may be media instead of text
+	isMessageStep := hasName && !hasMessage && !isSnooze && !isEdit
+	if message.Text == "" && !isMessageStep {
+		h.processDefault(message, "Message format not recognised.")
+		return
+	}
+
+	if isSnooze {
+		// process custom snooze time of a delivered reminder
+		h.processSnoozeSchedule(message, chatContextMap, chat.TimeZone)
+		return
+	}
+
+	if isEdit {
+		// process new value of a field chosen through /editjob
+		h.processEditJob(message, chatContextMap, chat.TimeZone)
+		return
+	}
+
 	if len(chatContextMap) == 0 {
 		// process 1st input of /newjob
 		h.processJobName(message, chatContextMap)
 		return
 	}
 
-	if _, exists := chatContextMap["name"]; exists && len(chatContextMap) == 1 {
+	if isMessageStep {
 		// process 2nd input of /newjob
 		h.processJobMessage(message, chatContextMap)
 		return
 	}
 
-	if _, exists := chatContextMap["is_recurring"]; exists && len(chatContextMap) == 3 {
+	_, hasSchedule := chatContextMap["schedule"]
+	_, hasEnd := chatContextMap["end_date"]
+	if hasSchedule && !hasEnd && chatContextMap["is_recurring"] == "true" {
+		// process 5th input of /newjob for recurring jobs
+		h.processJobEnd(message, chatContextMap, chat.TimeZone)
+		return
+	}
+
+	if _, exists := chatContextMap["is_recurring"]; exists && !hasSchedule {
 		// process 4th input of /newjob
-		h.processJobSchedule(message, chatContextMap)
+		h.processJobSchedule(message, chatContextMap, chat.TimeZone)
 		return
 	}
 
@@ -87,15 +118,15 @@ func (h *Handler) ProcessMessage(message *tgbotapi.Message) {
 }
 
 func (h *Handler) sendErrorMessage(err error, message *tgbotapi.Message) {
-	if err := h.botClient.SendPlainMessage(message.Chat.ID, fmt.Sprintf("An error occurred processing the message: %v",
-		err.Error())); err != nil {
+	if err := h.botClient.SendPlainMessage(context.Background(), message.Chat.ID,
+		fmt.Sprintf("An error occurred processing the message: %v", err.Error())); err != nil {
 		log.Warn().Err(err).Msgf("Unable to publish error message [user: %s][message: %v].", message.From.UserName,
 			err.Error())
 	}
 }
 
 func (h *Handler) processDefault(message *tgbotapi.Message, displayMessage string) {
-	if err := h.botClient.SendPlainMessage(message.Chat.ID,
+	if err := h.botClient.SendPlainMessage(context.Background(), message.Chat.ID,
 		fmt.Sprintf("%s\n\nDid you mean to enter a command? Please input /start to view the list of available"+
 			" commands.", displayMessage)); err != nil {
 		log.Err(err).Msgf("Unable to respond to unknown message context [user: %s].", message.From.UserName)
@@ -104,13 +135,17 @@ func (h *Handler) processDefault(message *tgbotapi.Message, displayMessage strin
 }
 
 func (h *Handler) processJobName(message *tgbotapi.Message, contextMap map[string]string) {
-	name, err := validateJobName(message.Text)
+	name, tags := callbackqueries.SplitNameTags(message.Text)
+	name, err := validateJobName(name)
 	if err != nil {
 		h.sendErrorMessage(err, message)
 		return
 	}
 
 	contextMap["name"] = name
+	if len(tags) > 0 {
+		contextMap["tags"] = strings.Join(tags, ",")
+	}
 	contextMapBytes, err := json.Marshal(contextMap)
 	if err != nil {
 		log.Err(err).Msgf("Unable to marshal chat context [contextMap: %+v].", contextMap)
@@ -127,20 +162,33 @@ func (h *Handler) processJobName(message *tgbotapi.Message, contextMap map[strin
 		return
 	}
 
-	if err := h.botClient.SendPlainMessage(message.Chat.ID, "Please input the message to be scheduled."); err != nil {
+	if err := h.botClient.SendPlainMessage(context.Background(), message.Chat.ID,
+		"Please input the message to be scheduled. It can also "+
+			"be a photo, document or voice note, with its caption as the message, or a sticker."); err != nil {
 		log.Err(err).Msgf("Unable to send request for job message [user: %s].", message.From.UserName)
 		return
 	}
 }
 
 func (h *Handler) processJobMessage(message *tgbotapi.Message, contextMap map[string]string) {
-	text, err := validateJobMessage(message.Text)
-	if err != nil {
-		h.sendErrorMessage(err, message)
+	if media, ok := bot.MediaFromMessage(message); ok {
+		// the caption of media is its message, which stickers cannot have
+		contextMap["message"] = strings.TrimSpace(message.Caption)
+		contextMap["media_type"] = media.Type
+		contextMap["media_file_id"] = media.FileID
+	} else if message.Text == "" {
+		h.processDefault(message, "Message format not recognised, please input text, a photo, a document, a voice "+
+			"note or a sticker.")
 		return
+	} else {
+		text, err := validateJobMessage(message.Text)
+		if err != nil {
+			h.sendErrorMessage(err, message)
+			return
+		}
+		contextMap["message"] = text
 	}
 
-	contextMap["message"] = text
 	contextMapBytes, err := json.Marshal(contextMap)
 	if err != nil {
 		log.Err(err).Msgf("Unable to marshal chat context [contextMap: %+v].", contextMap)
@@ -157,20 +205,16 @@ func (h *Handler) processJobMessage(message *tgbotapi.Message, contextMap map[st
 		return
 	}
 
-	buttons := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Once-off", callbackqueries.ScheduledQueryData),
-		),
-		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Recurring", callbackqueries.PeriodicQueryData)),
-	)
-	if err := h.botClient.SendHtmlMessage(message.Chat.ID, "Select message schedule type.", buttons); err != nil {
+	if err := h.botClient.SendHtmlMessage(context.Background(), message.Chat.ID, "Select message schedule type.",
+		callbackqueries.NewJobTypeKeyboard()); err != nil {
 		log.Err(err).Msgf("Unable to send html message [telegramChatID: %v].", message.Chat.ID)
 		h.sendErrorMessage(err, message)
 		return
 	}
 }
 
-func (h *Handler) processJobSchedule(message *tgbotapi.Message, contextMap map[string]string) {
+func (h *Handler) processJobSchedule(message *tgbotapi.Message, contextMap map[string]string, timeZone string) {
+	loc := riverjobs.LoadLocation(timeZone)
 	isRecurring := contextMap["is_recurring"]
 	var (
 		schedule string
@@ -178,22 +222,25 @@ func (h *Handler) processJobSchedule(message *tgbotapi.Message, contextMap map[s
 	)
 
 	if isRecurring == "true" {
-		schedule, err = validateCronTab(message.Text)
-		if err != nil {
-			aiSchedule := h.useAI(message)
+		if recurrence, err := riverjobs.ParseInterval(message.Text, loc); err == nil {
+			schedule = recurrence.CronTab
+			contextMap["interval_seconds"] = strconv.Itoa(int(recurrence.Interval / time.Second))
+			contextMap["anchor_at"] = recurrence.AnchorAt.Format(time.DateTime)
+		} else if contextMap["is_interval"] == "true" {
+			h.sendErrorMessage(err, message)
+			return
+		} else if schedule, err = validateCronTab(message.Text); err != nil {
+			aiSchedule := h.useAI(message, loc.String())
 			if aiSchedule == "" {
 				return
 			}
 			schedule = aiSchedule
 		}
 
-	} else {
-		ts, err := validateScheduleTimestamp(message.Text)
-		if err != nil {
-			h.sendErrorMessage(err, message)
-			return
-		}
+	} else if ts, err := validateScheduleTimestamp(message.Text, loc); err == nil {
 		schedule = ts.Format(time.DateTime)
+	} else if schedule = h.useAIForTimestamp(message, loc); schedule == "" {
+		return
 	}
 
 	contextMap["schedule"] = schedule
@@ -213,15 +260,108 @@ func (h *Handler) processJobSchedule(message *tgbotapi.Message, contextMap map[s
 		return
 	}
 
+	if isRecurring == "true" {
+		if err := h.botClient.SendPlainMessage(context.Background(), message.Chat.ID,
+			"When should this recurring message stop?\n\n"+
+				"Input until YYYY-MM-DD to stop after that date (e.g. until 2025-12-31), N times to stop after N "+
+				"messages (e.g. 10 times), or none to keep it running until cancelled."); err != nil {
+			log.Err(err).Msgf("Unable to send request for job end [user: %s].", message.From.UserName)
+		}
+		return
+	}
+
+	h.sendJobConfirmation(message, contextMap, loc)
+}
+
+func (h *Handler) processJobEnd(message *tgbotapi.Message, contextMap map[string]string, timeZone string) {
+	loc := riverjobs.LoadLocation(timeZone)
+	recurrence, err := riverjobs.ParseRecurrence(contextMap["schedule"], contextMap["interval_seconds"],
+		contextMap["anchor_at"])
+	if err != nil {
+		log.Err(err).Msgf("Unable to parse recurrence [contextMap: %+v].", contextMap)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	endDate, maxOccurrences, err := validateJobEnd(message.Text, recurrence, loc)
+	if err != nil {
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	contextMap["end_date"] = endDate
+	contextMap["max_occurrences"] = maxOccurrences
+	contextMapBytes, err := json.Marshal(contextMap)
+	if err != nil {
+		log.Err(err).Msgf("Unable to marshal chat context [contextMap: %+v].", contextMap)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	if _, err := h.queries.UpdateChatContext(context.Background(), sqlc.UpdateChatContextParams{
+		TelegramChatID: message.Chat.ID,
+		Context:        contextMapBytes,
+	}); err != nil {
+		log.Err(err).Msgf("Unable to update chat context [telegramChatID: %v][context: %+v].", message.Chat.ID, contextMap)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	h.sendJobConfirmation(message, contextMap, loc)
+}
+
+func (h *Handler) sendJobConfirmation(message *tgbotapi.Message, contextMap map[string]string, loc *time.Location) {
 	button := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("Confirm", callbackqueries.ConfirmJobQueryData),
 		))
 
-	confirmationMsg := generateConfirmationMessage(contextMap)
-	if err := h.botClient.SendHtmlMessage(message.Chat.ID, confirmationMsg, button); err != nil {
+	confirmationMsg := generateConfirmationMessage(contextMap, loc)
+	if err := h.botClient.SendHtmlMessage(context.Background(), message.Chat.ID, confirmationMsg, button); err != nil {
 		log.Err(err).Msgf("Unable to send html message [telegramChatID: %v].", message.Chat.ID)
 		h.sendErrorMessage(err, message)
 		return
 	}
 }
+
+func (h *Handler) processSnoozeSchedule(message *tgbotapi.Message, contextMap map[string]string, timeZone string) {
+	loc := riverjobs.LoadLocation(timeZone)
+	snoozeUntil, err := validateScheduleTimestamp(message.Text, loc)
+	if err != nil {
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	deliveryID, _ := strconv.Atoi(contextMap["snooze_delivery_id"])
+	media := bot.Media{Type: contextMap["snooze_media_type"], FileID: contextMap["snooze_media_file_id"]}
+	if _, err := h.riverClient.AddSnoozeJob(int32(deliveryID), contextMap["snooze_message"], media, message.Chat.ID,
+		snoozeUntil); err != nil {
+		log.Err(err).Msgf("Unable to add snooze job to river client [telegramChatID: %v].", message.Chat.ID)
+		h.sendErrorMessage(err, message)
+		return
+	}
+
+	if _, err := h.queries.UpdateChatContext(context.Background(), sqlc.UpdateChatContextParams{
+		TelegramChatID: message.Chat.ID,
+		Context:        []byte("{}"),
+	}); err != nil {
+		log.Err(err).Msgf("Unable to update empty chat context [telegramChatID: %v].", message.Chat.ID)
+	}
+
+	// edit the delivered reminder to remove the snooze buttons
+	if messageID, err := strconv.Atoi(contextMap["snooze_message_id"]); err == nil {
+		text := fmt.Sprintf("%s\n\n⏰ Snoozed until %s", contextMap["snooze_message"],
+			riverjobs.FormatLocalTime(snoozeUntil, loc))
+		if err := h.botClient.SendEditMediaMessage(context.Background(), message.Chat.ID, messageID, media,
+			text); err != nil {
+			log.Warn().Err(err).Msgf("Unable to edit reminder to show snooze [telegramChatID: %v].", message.Chat.ID)
+		}
+	}
+
+	if err := h.botClient.SendPlainMessage(context.Background(), message.Chat.ID,
+		fmt.Sprintf("Successfully snoozed reminder until %s",
+			riverjobs.FormatLocalTime(snoozeUntil, loc))); err != nil {
+		log.Err(err).Msgf("Unable to send success message for snooze [user: %s].", message.From.UserName)
+		return
+	}
+}
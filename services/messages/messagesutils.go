@@ -1,18 +1,26 @@
 package messages
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/cohesion-org/deepseek-go"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-	"github.com/jsuar/go-cron-descriptor/pkg/crondescriptor"
-	"github.com/robfig/cron/v3"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/rs/zerolog/log"
 
+	"remembertelebot/bot"
 	"remembertelebot/deepseekai"
+	"remembertelebot/riverjobs"
+	"remembertelebot/services/callbackqueries"
+)
+
+const (
+	maxJobOccurrences = 1000
 )
 
 func validateJobName(text string) (string, error) {
@@ -34,57 +42,162 @@ func validateJobMessage(text string) (string, error) {
 	return msg, nil
 }
 
-func validateScheduleTimestamp(text string) (time.Time, error) {
+func validateScheduleTimestamp(text string, loc *time.Location) (time.Time, error) {
 	text = strings.TrimSpace(text)
 	now := time.Now()
 
-	timestamp, err := time.Parse(time.DateTime, text)
+	timestamp, err := time.ParseInLocation(time.DateTime, text, loc)
 	if err != nil {
-		return now, err
+		naturalTimestamp, naturalErr := riverjobs.ParseNaturalTime(text, now.In(loc))
+		if naturalErr != nil {
+			return now, err
+		}
+		timestamp = naturalTimestamp
 	}
 
 	if !timestamp.After(now) {
 		return now, errors.New("timestamp must be in the future")
 	}
 
-	return timestamp, nil
+	return timestamp.UTC(), nil
 }
 
 func validateCronTab(text string) (string, error) {
 	text = strings.TrimSpace(text)
-	if _, err := cron.ParseStandard(text); err != nil {
+	if riverjobs.IsExtendedCronTab(text) {
+		text = strings.Join(strings.Fields(strings.ToUpper(text)), " ")
+	}
+	schedule, err := riverjobs.ParseCronTab(text, time.UTC)
+	if err != nil {
 		return "", err
 	}
+	// a crontab such as 0 9 30 2 * parses, but never runs
+	if schedule.Next(time.Now()).IsZero() {
+		return "", errors.New("the schedule never runs")
+	}
 	return text, nil
 }
 
-func GetCronDescriptor(cronTab string) string {
-	cd, _ := crondescriptor.NewCronDescriptor(cronTab)
-	if cd != nil {
-		description, _ := cd.GetDescription(crondescriptor.Full)
-		return *description
+// validateJobEnd parses the optional bound of a recurring job: "until YYYY-MM-DD", "N times" or "none".
+func validateJobEnd(text string, recurrence riverjobs.Recurrence, loc *time.Location) (string, string, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "none" {
+		return "", "", nil
 	}
-	return ""
+
+	if date, ok := strings.CutPrefix(text, "until "); ok {
+		date = strings.TrimSpace(date)
+		endsAt, err := riverjobs.EndOfDate(date, loc)
+		if err != nil {
+			return "", "", errors.New("please input the end date in the format YYYY-MM-DD")
+		}
+		schedule, err := recurrence.Schedule(loc)
+		if err != nil {
+			return "", "", err
+		}
+		if next := schedule.Next(time.Now()); next.IsZero() || !next.Before(endsAt) {
+			return "", "", errors.New("the schedule does not run before the end date")
+		}
+		return date, "", nil
+	}
+
+	var times int
+	if _, err := fmt.Sscanf(text, "%d times", &times); err == nil {
+		if times < 1 || times > maxJobOccurrences {
+			return "", "", fmt.Errorf("number of times must be between 1 and %d", maxJobOccurrences)
+		}
+		return "", strconv.Itoa(times), nil
+	}
+
+	return "", "", errors.New("please input until YYYY-MM-DD, N times or none")
+}
+
+// validateJobReschedule checks that a recurring job given a new recurrence still runs before its end date and
+// occurrence limit, so that editing its schedule does not end it.
+func validateJobReschedule(job riverjobs.UpcomingJob, loc *time.Location) error {
+	occurrences, err := riverjobs.UpcomingOccurrences(context.Background(), nil, job, loc, 1)
+	if err != nil {
+		return err
+	}
+	if len(occurrences) == 0 {
+		return errors.New("the schedule does not run again before the job ends")
+	}
+	return nil
 }
 
-func generateConfirmationMessage(contextMap map[string]string) string {
+// upcomingJobFromContext is the recurring job being created in a chat context, as a riverjobs.UpcomingJob.
+func upcomingJobFromContext(contextMap map[string]string, recurrence riverjobs.Recurrence,
+	loc *time.Location) riverjobs.UpcomingJob {
+	job := riverjobs.UpcomingJob{Recurrence: recurrence}
+	if endsAt, err := riverjobs.EndOfDate(contextMap["end_date"], loc); err == nil {
+		job.EndsAt = pgtype.Timestamp{Valid: true, Time: endsAt}
+	}
+	if times, err := strconv.Atoi(contextMap["max_occurrences"]); err == nil {
+		job.MaxOccurrences = pgtype.Int4{Valid: true, Int32: int32(times)}
+	}
+	return job
+}
+
+func generateConfirmationMessage(contextMap map[string]string, loc *time.Location) string {
 	name := contextMap["name"]
 	isRecurring := contextMap["is_recurring"]
-	message := contextMap["message"]
+	message := bot.DescribeMediaMessage(contextMap["media_type"], contextMap["message"])
 	schedule := contextMap["schedule"]
 
-	scheduleText := fmt.Sprintf("Once-off, at UTC %s", schedule)
+	scheduleText := fmt.Sprintf("Once-off, at %s", riverjobs.FormatLocalTimestamp(schedule, loc))
 	if isRecurring == "true" {
-		scheduleText = fmt.Sprintf("Recurring at UTC <b>%s</b> (%s)", schedule, GetCronDescriptor(schedule))
+		recurrence, err := riverjobs.ParseRecurrence(schedule, contextMap["interval_seconds"], contextMap["anchor_at"])
+		if err != nil {
+			log.Warn().Err(err).Msgf("Unable to parse recurrence [contextMap: %+v].", contextMap)
+		}
+		occurrences, err := riverjobs.UpcomingOccurrences(context.Background(), nil,
+			upcomingJobFromContext(contextMap, recurrence, loc), loc, riverjobs.PreviewOccurrences)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Unable to get upcoming occurrences [contextMap: %+v].", contextMap)
+		}
+		scheduleText = fmt.Sprintf("<b>%s</b>\n<b>Ends:</b> %s\n<b>Next runs:</b>\n%s",
+			riverjobs.DescribeRecurrence(recurrence, loc), riverjobs.FormatJobEnd(contextMap["end_date"], contextMap["max_occurrences"]),
+			riverjobs.FormatOccurrences(occurrences, loc))
 	}
 
-	return fmt.Sprintf("Please confirm the following job details:\n\n<b>Job name:</b> %s\n<b>Message to send:</b> %s\n<b"+
+	tagsText := ""
+	if tags := contextMap["tags"]; tags != "" {
+		tagsText = fmt.Sprintf("\n<b>Tags:</b> %s", callbackqueries.FormatTags(strings.Split(tags, ",")))
+	}
+
+	return fmt.Sprintf("Please confirm the following job details:\n\n<b>Job name:</b> %s%s\n<b>Message to send:</b> %s\n<b"+
 		">Schedule"+
 		":</b> %s"+
-		"", name, message, scheduleText)
+		"", name, tagsText, message, scheduleText)
+}
+
+// useAI converses with the AI until it confirms a cron tab for the recurring schedule described in message.
+func (h *Handler) useAI(message *tgbotapi.Message, timeZone string) string {
+	return h.converseAI(message, fmt.Sprintf(deepseekai.Prompt, timeZone), "final cron is ", validateCronTab)
+}
+
+// useAIForTimestamp converses with the AI until it confirms a future once-off time described in message, returned
+// in UTC and in the time.DateTime format.
+func (h *Handler) useAIForTimestamp(message *tgbotapi.Message, loc *time.Location) string {
+	now := time.Now().In(loc)
+	systemPrompt := fmt.Sprintf(deepseekai.TimestampPrompt, loc.String(), now.Format("Monday, 2006-01-02 15:04:05"))
+	return h.converseAI(message, systemPrompt, "final timestamp is ", func(text string) (string, error) {
+		timestamp, err := time.ParseInLocation(time.DateTime, strings.TrimSpace(text), loc)
+		if err != nil {
+			return "", err
+		}
+		if !timestamp.After(time.Now()) {
+			return "", errors.New("timestamp must be in the future")
+		}
+		return timestamp.UTC().Format(time.DateTime), nil
+	})
 }
 
-func (h *Handler) useAI(message *tgbotapi.Message) string {
+// converseAI continues the chat's AI conversation with message. Once the AI answers with finalPrefix followed by a
+// result that passes validate, the conversation is cleared and the validated result is returned; otherwise the AI's
+// reply is relayed to the user and an empty string is returned.
+func (h *Handler) converseAI(message *tgbotapi.Message, systemPrompt string, finalPrefix string,
+	validate func(string) (string, error)) string {
 	cacheKey := fmt.Sprintf("%d", message.Chat.ID)
 	value, err := h.cache.Get(cacheKey)
 	if err != nil {
@@ -98,7 +211,7 @@ func (h *Handler) useAI(message *tgbotapi.Message) string {
 	// formulate messages array (depending on cache hit)
 	messages := []deepseek.ChatCompletionMessage{{
 		Role:    deepseek.ChatMessageRoleSystem,
-		Content: deepseekai.Prompt,
+		Content: systemPrompt,
 	},
 		newMessage,
 	}
@@ -114,18 +227,21 @@ func (h *Handler) useAI(message *tgbotapi.Message) string {
 	}
 
 	// parse AI response
-	if strings.Contains(aiResponse.Content, "final cron is ") {
-		cronTab := strings.ReplaceAll(aiResponse.Content, "final cron is ", "")
-		cronTab = strings.ReplaceAll(cronTab, "`", "")
-		schedule, err := validateCronTab(cronTab)
+	replyText := aiResponse.Content
+	if strings.Contains(aiResponse.Content, finalPrefix) {
+		result := strings.ReplaceAll(aiResponse.Content, finalPrefix, "")
+		result = strings.ReplaceAll(result, "`", "")
+		validResult, err := validate(result)
 		if err == nil {
 			h.cache.Delete(cacheKey)
-			return schedule
+			return validResult
 		}
+		log.Warn().Err(err).Msgf("Unable to validate AI result [result: %s].", result)
+		replyText = fmt.Sprintf("Sorry, that did not work out (%v). Please describe it again.", err)
 	}
 
 	// send AI response to user
-	if err := h.botClient.SendPlainMessage(message.Chat.ID, aiResponse.Content); err != nil {
+	if err := h.botClient.SendPlainMessage(context.Background(), message.Chat.ID, replyText); err != nil {
 		h.sendErrorMessage(err, message)
 	}
 
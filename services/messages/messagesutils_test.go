@@ -0,0 +1,122 @@
+package messages
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"remembertelebot/riverjobs"
+)
+
+func TestValidateCronTab(t *testing.T) {
+	tests := []struct {
+		cronTab string
+		want    string
+		wantErr bool
+	}{
+		{cronTab: " 0 9 * * MON ", want: "0 9 * * MON"},
+		{cronTab: "0 9 l * *", want: "0 9 L * *"},
+		{cronTab: "0 9 * * tue#2", want: "0 9 * * TUE#2"},
+		{cronTab: "0 9 30 2 *", wantErr: true},
+		{cronTab: "0 9 31W 2 *", wantErr: true},
+		{cronTab: "0 9 * *", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cronTab, func(t *testing.T) {
+			got, err := validateCronTab(tt.cronTab)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateCronTab(%q) error = %v, wantErr %v", tt.cronTab, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("validateCronTab(%q) = %q, want %q", tt.cronTab, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateJobEnd(t *testing.T) {
+	loc := time.UTC
+	nextYear := strconv.Itoa(time.Now().Year() + 1)
+	daily := riverjobs.Recurrence{CronTab: "0 9 * * *"}
+	never := riverjobs.Recurrence{CronTab: "0 9 31W 2 *"}
+
+	tests := []struct {
+		name           string
+		text           string
+		recurrence     riverjobs.Recurrence
+		wantEndDate    string
+		wantOccurrence string
+		wantErr        bool
+	}{
+		{name: "none", text: " None ", recurrence: daily},
+		{name: "end date", text: "until " + nextYear + "-01-01", recurrence: daily, wantEndDate: nextYear + "-01-01"},
+		{name: "times", text: "3 times", recurrence: daily, wantOccurrence: "3"},
+		{name: "past end date", text: "until 2000-01-01", recurrence: daily, wantErr: true},
+		{name: "schedule that never runs", text: "until " + nextYear + "-01-01", recurrence: never, wantErr: true},
+		{name: "invalid end date", text: "until tomorrow", recurrence: daily, wantErr: true},
+		{name: "zero times", text: "0 times", recurrence: daily, wantErr: true},
+		{name: "too many times", text: "1001 times", recurrence: daily, wantErr: true},
+		{name: "unknown", text: "forever", recurrence: daily, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endDate, occurrences, err := validateJobEnd(tt.text, tt.recurrence, loc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateJobEnd(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			}
+			if endDate != tt.wantEndDate || occurrences != tt.wantOccurrence {
+				t.Errorf("validateJobEnd(%q) = %q, %q, want %q, %q", tt.text, endDate, occurrences, tt.wantEndDate,
+					tt.wantOccurrence)
+			}
+		})
+	}
+}
+
+func TestValidateJobReschedule(t *testing.T) {
+	daily := riverjobs.Recurrence{CronTab: "0 9 * * *"}
+	nextWeek := riverjobs.Recurrence{Interval: 24 * time.Hour, AnchorAt: time.Now().AddDate(0, 0, 7)}
+	nextYear := pgtype.Timestamp{Valid: true, Time: time.Now().AddDate(1, 0, 0)}
+
+	tests := []struct {
+		name    string
+		job     riverjobs.UpcomingJob
+		wantErr bool
+	}{
+		{name: "unbounded", job: riverjobs.UpcomingJob{Recurrence: daily}},
+		{name: "before the end date", job: riverjobs.UpcomingJob{Recurrence: daily, EndsAt: nextYear}},
+		{
+			name: "below the occurrence limit",
+			job: riverjobs.UpcomingJob{Recurrence: daily, MaxOccurrences: pgtype.Int4{Valid: true, Int32: 3},
+				OccurrenceCount: 2},
+		},
+		{
+			name: "only after the end date",
+			job: riverjobs.UpcomingJob{Recurrence: nextWeek, EndsAt: pgtype.Timestamp{Valid: true,
+				Time: time.Now().AddDate(0, 0, 1)}},
+			wantErr: true,
+		},
+		{
+			name: "at the occurrence limit",
+			job: riverjobs.UpcomingJob{Recurrence: daily, MaxOccurrences: pgtype.Int4{Valid: true, Int32: 3},
+				OccurrenceCount: 3},
+			wantErr: true,
+		},
+		{
+			name:    "never runs",
+			job:     riverjobs.UpcomingJob{Recurrence: riverjobs.Recurrence{CronTab: "0 9 31W 2 *"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateJobReschedule(tt.job, time.UTC); (err != nil) != tt.wantErr {
+				t.Errorf("validateJobReschedule(%+v) error = %v, wantErr %v", tt.job, err, tt.wantErr)
+			}
+		})
+	}
+}